@@ -0,0 +1,138 @@
+package bitemporal
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+// Aggregation evaluates an aggregate over the records of a registered table valid at each moment of a time series
+type Aggregation struct {
+	Table string
+	// Expression is the aggregate evaluated over the records valid at a moment, e.g. "COUNT(t.emp_no)" or
+	// "SUM(salary)". Without GroupBy a moment where no record is valid is a single row of NULLs, so count a column of
+	// t: COUNT(*) is 1 there.
+	Expression string
+	// GroupBy splits the series by these columns, e.g. dept_no. Moments where a group has no record are left out.
+	GroupBy []string
+	// Where restricts the records aggregated, they are aliased t
+	Where  string
+	ArgMap map[string]any
+
+	ValidFrom time.Time
+	ValidTo   time.Time
+	// Every samples the range at fixed intervals, given as an SQLite date modifier such as "+1 month", it is only
+	// supported by the SQLite dialect. When empty the range is sampled at its start and at every change point of the
+	// table within it.
+	Every string
+}
+
+// Sample is the value of an aggregate at a moment of valid time
+type Sample struct {
+	At    time.Time      `json:"at"`
+	Group map[string]any `json:"group,omitempty"`
+	Value any            `json:"value"`
+}
+
+const (
+	changePointsQuery = `SELECT @series_open at
+UNION SELECT valid_open FROM %[1]s
+    WHERE %[2]s AND @series_open < valid_open AND valid_open < @series_close
+UNION SELECT valid_close FROM %[1]s
+    WHERE %[2]s AND @series_open < valid_close AND valid_close < @series_close`
+
+	// the modifier is applied to the seconds and the fraction of the canonical encoding is carried over
+	intervalPointsQuery = `WITH RECURSIVE points(at) AS (
+    SELECT @series_open
+    UNION ALL
+    SELECT STRFTIME('%Y-%m-%dT%H:%M:%S', at, @series_every) || SUBSTR(at, 20) FROM points
+    WHERE STRFTIME('%Y-%m-%dT%H:%M:%S', at, @series_every) || SUBSTR(at, 20) < @series_close
+) SELECT at FROM points`
+)
+
+// Aggregate returns the time series of the aggregation over [ValidFrom, ValidTo), ordered by moment and group. The
+// records are those known at the system moment of the TemporalContext, or now.
+func (repo *TemporalDB) Aggregate(ctx context.Context, agg Aggregation) ([]Sample, error) {
+	t, ok := repo.table(agg.Table)
+	if !ok {
+		return nil, fmt.Errorf("table %q is not registered", agg.Table)
+	}
+	if !agg.ValidFrom.Before(agg.ValidTo) {
+		return nil, fmt.Errorf("aggregation on %q has an empty valid range", agg.Table)
+	}
+
+	args := maps.Clone(agg.ArgMap)
+	if args == nil {
+		args = make(map[string]any)
+	}
+	args["series_open"] = agg.ValidFrom
+	args["series_close"] = agg.ValidTo
+
+	where := "TRUE"
+	if agg.Where != "" {
+		where = "(" + agg.Where + ")"
+	}
+
+	points := intervalPointsQuery
+	if agg.Every != "" {
+		if err := repo.requireSQLite("sampling at intervals"); err != nil {
+			return nil, err
+		}
+		args["series_every"] = agg.Every
+	} else {
+		points = fmt.Sprintf(changePointsQuery, repo.View(t.Name, "t"), where)
+	}
+
+	join, groups := "LEFT JOIN", ""
+	for _, column := range agg.GroupBy {
+		if !slices.Contains(t.Columns, column) {
+			return nil, fmt.Errorf("table %q has no column %q", t.Name, column)
+		}
+		groups += ", t." + column
+	}
+	if groups != "" {
+		// a group has no row to report where it has no record
+		join = "JOIN"
+	}
+
+	query := fmt.Sprintf(`SELECT p.at%[1]s, %[2]s
+FROM (%[3]s) p
+%[4]s %[5]s ON t.valid_open <= p.at AND p.at < t.valid_close AND %[6]s
+GROUP BY p.at%[1]s
+ORDER BY p.at%[1]s`, groups, agg.Expression, points, join, repo.View(t.Name, "t"), where)
+
+	// every version overlapping the range, as known at one moment
+	ctx = WithSystemMoment(WithValidRange(ctx, agg.ValidFrom, agg.ValidTo), repo.systemMoment(ctx))
+	rows, err := repo.Query(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var samples []Sample
+	for rows.Next() {
+		record := make([]any, len(agg.GroupBy)+2)
+		ptrs := make([]any, len(record))
+		for i := range record {
+			ptrs[i] = &record[i]
+		}
+		if err = rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+
+		sample := Sample{Value: record[len(record)-1]}
+		if sample.At, err = asMoment(record[0]); err != nil {
+			return nil, err
+		}
+		if len(agg.GroupBy) > 0 {
+			sample.Group = make(map[string]any, len(agg.GroupBy))
+			for i, column := range agg.GroupBy {
+				sample.Group[column] = record[i+1]
+			}
+		}
+		samples = append(samples, sample)
+	}
+	return samples, rows.Err()
+}
@@ -0,0 +1,101 @@
+package bitemporal_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/pborges/bitemporal"
+)
+
+func TestAggregateChangePoints(t *testing.T) {
+	_, temporalDB, cleanup := createBatchTestDB(t)
+	defer cleanup()
+
+	// total payroll at each change point
+	samples, err := temporalDB.Aggregate(context.Background(), bitemporal.Aggregation{
+		Table:      "salaries",
+		Expression: "SUM(salary)",
+		ValidFrom:  bitemporal.AsTime("1999-01-01"),
+		ValidTo:    bitemporal.AsTime("2001-01-01"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []bitemporal.Sample{
+		{At: bitemporal.AsTime("1999-01-01"), Value: int64(89324 + 50000 + 40000)},
+		{At: bitemporal.AsTime("1999-02-15"), Value: int64(90668 + 50000 + 40000)},
+		{At: bitemporal.AsTime("2000-01-01"), Value: int64(90668 + 60000 + 40000)},
+		{At: bitemporal.AsTime("2000-02-15"), Value: int64(93507 + 60000 + 40000)},
+	}
+	if !reflect.DeepEqual(expected, samples) {
+		t.Errorf("Expected %v, got %v", expected, samples)
+	}
+}
+
+func TestAggregateEmpty(t *testing.T) {
+	_, temporalDB, cleanup := createBatchTestDB(t)
+	defer cleanup()
+
+	// nobody was paid before the company existed
+	samples, err := temporalDB.Aggregate(context.Background(), bitemporal.Aggregation{
+		Table:      "salaries",
+		Expression: "COUNT(t.emp_no)",
+		ValidFrom:  bitemporal.AsTime("1900-01-01"),
+		ValidTo:    bitemporal.AsTime("1901-01-01"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []bitemporal.Sample{{At: bitemporal.AsTime("1900-01-01"), Value: int64(0)}}
+	if !reflect.DeepEqual(expected, samples) {
+		t.Errorf("Expected %v, got %v", expected, samples)
+	}
+}
+
+func TestAggregateIntervals(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+	_, err := db.Exec(`INSERT INTO dept_emp (emp_no, dept_no, valid_open, valid_close, txn_open, txn_close)
+VALUES (10009, 'd005', '1990-01-01', '2000-03-15', '2025-08-23 08:55:49', '9999-12-31 23:59:59'),
+       (10010, 'd005', '2000-01-01', '9999-12-31 23:59:59', '2025-08-23 08:55:49', '9999-12-31 23:59:59'),
+       (10011, 'd004', '2000-02-01', '9999-12-31 23:59:59', '2025-08-23 08:55:49', '9999-12-31 23:59:59')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	temporalDB, err := bitemporal.NewTemporalDB(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// headcount per department per month
+	samples, err := temporalDB.Aggregate(context.Background(), bitemporal.Aggregation{
+		Table:      "dept_emp",
+		Expression: "COUNT(*)",
+		GroupBy:    []string{"dept_no"},
+		ValidFrom:  bitemporal.AsTime("2000-01-01"),
+		ValidTo:    bitemporal.AsTime("2000-05-01"),
+		Every:      "+1 month",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sample := func(at, dept string, count int64) bitemporal.Sample {
+		return bitemporal.Sample{At: bitemporal.AsTime(at), Group: map[string]any{"dept_no": dept}, Value: count}
+	}
+	expected := []bitemporal.Sample{
+		sample("2000-01-01", "d005", 2),
+		sample("2000-02-01", "d004", 1),
+		sample("2000-02-01", "d005", 2),
+		sample("2000-03-01", "d004", 1),
+		sample("2000-03-01", "d005", 2),
+		sample("2000-04-01", "d004", 1),
+		sample("2000-04-01", "d005", 1),
+	}
+	if !reflect.DeepEqual(expected, samples) {
+		t.Errorf("Expected %v, got %v", expected, samples)
+	}
+}
@@ -0,0 +1,308 @@
+package bitemporal
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+//go:embed sql/batch_update_window.tmpl.sql
+var createBatchUpdateWindowQuery string
+
+//go:embed sql/close_batch_window.tmpl.sql
+var closeBatchUpdateWindowQuery string
+
+// batchKeysTable is the temporary table holding the keys of a batch while it is applied
+const batchKeysTable = "bitemporal_batch_keys"
+
+// BatchUpdateWindow applies the same valid-time window to many entities of one table in a single transaction
+type BatchUpdateWindow struct {
+	Table    string
+	Select   []string
+	FilterBy []string
+
+	// Keys holds the FilterBy values of every entity to update
+	Keys []map[string]any
+	// KeyQuery selects the FilterBy columns of every entity to update instead of Keys, it may use the temporal
+	// views of the TemporalContext and the parameters in KeyArgs
+	KeyQuery string
+	KeyArgs  map[string]any
+
+	// Values holds the value of every selected column that is not a key column or computed by Expressions
+	Values map[string]any
+	// Expressions computes columns from the superseded record, e.g. "salary * 103 / 100". The superseded record is
+	// aliased t, qualify key columns with it. As there is nothing to compute from, no periods are written where an
+	// entity has no current record.
+	Expressions map[string]string
+
+	ValidFrom time.Time
+	ValidTo   time.Time
+
+	// TxnMoment is the transaction moment the new periods are recorded at, see UpdateWindow.TxnMoment
+	TxnMoment time.Time
+}
+
+// BatchResult holds the periods written for a single key of a batch
+type BatchResult struct {
+	Key     map[string]any `json:"key"`
+	Periods []Period       `json:"periods"`
+}
+
+func (w BatchUpdateWindow) KeysTable() string {
+	return batchKeysTable
+}
+
+func (w BatchUpdateWindow) KeyColumnsString() string {
+	return strings.Join(w.FilterBy, ", ")
+}
+
+func (w BatchUpdateWindow) KeysOf(alias string) string {
+	columns := make([]string, len(w.FilterBy))
+	for i := range w.FilterBy {
+		columns[i] = alias + "." + w.FilterBy[i]
+	}
+	return strings.Join(columns, ", ")
+}
+
+func (w BatchUpdateWindow) ColumnsOf(alias string) string {
+	columns := make([]string, len(w.Select))
+	for i := range w.Select {
+		columns[i] = alias + "." + w.Select[i] + " as " + w.Select[i]
+	}
+	return strings.Join(columns, ", ")
+}
+
+func (w BatchUpdateWindow) UpdateColumnsString() string {
+	columns := make([]string, len(w.Select))
+	for i, column := range w.Select {
+		if expression, ok := w.Expressions[column]; ok {
+			columns[i] = "(" + expression + ") as " + column
+		} else if slices.Contains(w.FilterBy, column) {
+			columns[i] = "t." + column + " as " + column
+		} else {
+			columns[i] = "@" + column + " as " + column
+		}
+	}
+	return strings.Join(columns, ", ")
+}
+
+func (w BatchUpdateWindow) NewColumnsString() string {
+	columns := make([]string, len(w.Select))
+	for i, column := range w.Select {
+		if slices.Contains(w.FilterBy, column) {
+			columns[i] = "k." + column + " as " + column
+		} else {
+			columns[i] = "@" + column + " as " + column
+		}
+	}
+	return strings.Join(columns, ", ")
+}
+
+func (w BatchUpdateWindow) JoinString() string {
+	filters := make([]string, len(w.FilterBy))
+	for i := range w.FilterBy {
+		filters[i] = "k." + w.FilterBy[i] + " = t." + w.FilterBy[i]
+	}
+	return strings.Join(filters, " AND ")
+}
+
+func (w BatchUpdateWindow) validate() error {
+	if len(w.FilterBy) == 0 {
+		return fmt.Errorf("batch update window on %q has no key columns", w.Table)
+	}
+	if (len(w.Keys) == 0) == (w.KeyQuery == "") {
+		return fmt.Errorf("batch update window on %q requires either keys or a key query", w.Table)
+	}
+	if !w.ValidFrom.Before(w.ValidTo) {
+		return fmt.Errorf("batch update window on %q must be valid from before %s, got %s",
+			w.Table, w.ValidTo.Format(time.DateTime), w.ValidFrom.Format(time.DateTime))
+	}
+	for _, column := range w.FilterBy {
+		if !slices.Contains(w.Select, column) {
+			return fmt.Errorf("key column %q is not selected", column)
+		}
+	}
+	for _, column := range w.Select {
+		if slices.Contains(w.FilterBy, column) {
+			continue
+		}
+		if _, ok := w.Expressions[column]; ok {
+			continue
+		}
+		if _, ok := w.Values[column]; !ok {
+			return fmt.Errorf("value not found for column %q", column)
+		}
+	}
+	for _, key := range w.Keys {
+		for _, column := range w.FilterBy {
+			if _, ok := key[column]; !ok {
+				return fmt.Errorf("key %v has no value for column %q", key, column)
+			}
+		}
+	}
+	return nil
+}
+
+func renderBatchQuery(dialect Dialect, text string, window BatchUpdateWindow) (QueryFragment, error) {
+	tmpl, err := template.New("batch").Parse(text)
+	if err != nil {
+		return QueryFragment{}, err
+	}
+
+	fragment := QueryFragment{
+		ArgMap: map[string]any{
+			"valid_open":  window.ValidFrom,
+			"valid_close": window.ValidTo,
+			"txn_moment":  window.TxnMoment,
+		},
+	}
+	for _, column := range window.Select {
+		if val, ok := window.Values[column]; ok && !slices.Contains(window.FilterBy, column) {
+			fragment.ArgMap[column] = val
+		}
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, struct {
+		BatchUpdateWindow
+		Dialect Dialect
+	}{window, dialect})
+	if err != nil {
+		return QueryFragment{}, err
+	}
+	fragment.Query = buf.String()
+	return fragment, nil
+}
+
+// ApplyBatchUpdateWindow persists the window for every key of the batch: the keys are loaded into a temporary table
+// and the periods of all of them are computed, closed and inserted with set-based statements inside one
+// transaction, so every record shares one transaction moment. The result holds an entry for every key, in key order.
+func (repo *TemporalDB) ApplyBatchUpdateWindow(ctx context.Context, window BatchUpdateWindow) ([]BatchResult, error) {
+	if err := repo.requireSQLite("applying batch update windows"); err != nil {
+		return nil, err
+	}
+	if window.TxnMoment.IsZero() {
+		window.TxnMoment = repo.txnMoment(ctx)
+	}
+	if err := window.validate(); err != nil {
+		return nil, err
+	}
+
+	periodsFragment, err := renderBatchQuery(repo.dialect, createBatchUpdateWindowQuery, window)
+	if err != nil {
+		return nil, err
+	}
+	closeFragment, err := renderBatchQuery(repo.dialect, closeBatchUpdateWindowQuery, window)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS temp."+batchKeysTable); err != nil {
+		return nil, err
+	}
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf("CREATE TEMP TABLE %s (%s)", batchKeysTable, window.KeyColumnsString())); err != nil {
+		return nil, err
+	}
+
+	insertKeys := fmt.Sprintf("INSERT INTO %s (%s) ", batchKeysTable, window.KeyColumnsString())
+	if window.KeyQuery != "" {
+		query, args := repo.bind(repo.prepareQuery(ctx, QueryFragment{insertKeys + "SELECT DISTINCT * FROM (" + window.KeyQuery + ") keys", window.KeyArgs}))
+		if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+			return nil, err
+		}
+	} else {
+		insertKeys += "VALUES (@" + strings.Join(window.FilterBy, ", @") + ")"
+		seen := make(map[string]bool, len(window.Keys))
+		for _, key := range window.Keys {
+			values := make([]any, len(window.FilterBy))
+			for i, column := range window.FilterBy {
+				values[i] = key[column]
+			}
+			if id := fmt.Sprint(values); !seen[id] {
+				seen[id] = true
+				query, args := repo.bind(QueryFragment{insertKeys, key})
+				if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	keys, err := repo.queryRecords(ctx, tx, QueryFragment{Query: fmt.Sprintf("SELECT %s FROM %s ORDER BY %s",
+		window.KeyColumnsString(), batchKeysTable, window.KeyColumnsString())}, len(window.FilterBy))
+	if err != nil {
+		return nil, err
+	}
+	results := make([]BatchResult, len(keys))
+	index := make(map[string]int, len(keys))
+	for i, key := range keys {
+		results[i].Key = make(map[string]any, len(window.FilterBy))
+		for j, column := range window.FilterBy {
+			results[i].Key[column] = key[j]
+		}
+		index[fmt.Sprint(key)] = i
+	}
+
+	// the new periods must be computed before the current records are closed
+	records, err := repo.queryRecords(ctx, tx, periodsFragment, len(window.Select)+4)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args := repo.bind(closeFragment)
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+		return nil, err
+	}
+
+	if err = repo.insertRecords(ctx, tx, window.Table, window.Select, records); err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		period, err := recordToPeriod(window.Select, record)
+		if err != nil {
+			return nil, err
+		}
+
+		key := make([]any, len(window.FilterBy))
+		for i, column := range window.FilterBy {
+			key[i] = period.Values[column]
+		}
+		i, ok := index[fmt.Sprint(key)]
+		if !ok {
+			return nil, errors.New("batch update window wrote a period for an unknown key")
+		}
+		results[i].Periods = append(results[i].Periods, period)
+	}
+
+	if _, err = tx.ExecContext(ctx, "DROP TABLE temp."+batchKeysTable); err != nil {
+		return nil, err
+	}
+
+	write := Write{
+		Table:     window.Table,
+		Keys:      make([]map[string]any, len(results)),
+		ValidFrom: window.ValidFrom,
+		ValidTo:   window.ValidTo,
+		TxnMoment: window.TxnMoment,
+	}
+	for i := range results {
+		write.Keys[i] = results[i].Key
+	}
+	if err = repo.preCommit(ctx, tx, write); err != nil {
+		return nil, err
+	}
+	return results, tx.Commit()
+}
@@ -0,0 +1,155 @@
+package bitemporal_test
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/pborges/bitemporal"
+)
+
+const batchSalaries = `INSERT INTO salaries (emp_no, salary, valid_open, valid_close, txn_open, txn_close)
+VALUES (10010, 50000, '1990-01-01', '2000-01-01', '2025-08-23 08:55:49', '9999-12-31 23:59:59'),
+       (10010, 60000, '2000-01-01', '9999-12-31 23:59:59', '2025-08-23 08:55:49', '9999-12-31 23:59:59'),
+       (10011, 40000, '1997-01-01', '9999-12-31 23:59:59', '2025-08-23 08:55:49', '9999-12-31 23:59:59')`
+
+func createBatchTestDB(t *testing.T) (*sql.DB, *bitemporal.TemporalDB, func()) {
+	db, cleanup := createTestDB(t)
+	if _, err := db.Exec(batchSalaries); err != nil {
+		t.Fatal(err)
+	}
+	temporalDB, err := bitemporal.NewTemporalDB(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, temporalDB, cleanup
+}
+
+// TestBatchUpdateWindow verifies a batch writes the same periods as one update window per key
+func TestBatchUpdateWindow(t *testing.T) {
+	_, temporalDB, cleanup := createBatchTestDB(t)
+	defer cleanup()
+
+	results, err := temporalDB.ApplyBatchUpdateWindow(context.Background(), bitemporal.BatchUpdateWindow{
+		Table:    "salaries",
+		Select:   []string{"emp_no", "salary"},
+		FilterBy: []string{"emp_no"},
+		Keys: []map[string]any{
+			{"emp_no": 10009}, {"emp_no": 10010}, {"emp_no": 10011}, {"emp_no": 10099}, {"emp_no": 10010},
+		},
+		Values:    map[string]any{"salary": 42},
+		ValidFrom: bitemporal.AsTime("1995-01-01"),
+		ValidTo:   bitemporal.AsTime("2000-01-01"),
+		TxnMoment: testTxnMoment,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("Expected a result for each of the 4 distinct keys, got %d", len(results))
+	}
+
+	for _, result := range results {
+		_, single, singleCleanup := createBatchTestDB(t)
+		periods, err := single.ApplyUpdateWindow(context.Background(), bitemporal.UpdateWindow{
+			Table:     "salaries",
+			Select:    []string{"emp_no", "salary"},
+			FilterBy:  []string{"emp_no"},
+			Values:    map[string]any{"emp_no": result.Key["emp_no"], "salary": 42},
+			ValidFrom: bitemporal.AsTime("1995-01-01"),
+			ValidTo:   bitemporal.AsTime("2000-01-01"),
+			TxnMoment: testTxnMoment,
+		})
+		singleCleanup()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(periods) != len(result.Periods) {
+			t.Errorf("Expected %d periods for %v, got %d", len(periods), result.Key, len(result.Periods))
+			continue
+		}
+		for i := range periods {
+			if !periods[i].ValidOpen.Equal(result.Periods[i].ValidOpen) || !periods[i].ValidClose.Equal(result.Periods[i].ValidClose) ||
+				!reflect.DeepEqual(periods[i].Values["salary"], result.Periods[i].Values["salary"]) {
+				t.Errorf("Period %d of %v: expected %v, got %v", i, result.Key, periods[i], result.Periods[i])
+			}
+		}
+	}
+}
+
+func TestBatchUpdateWindowExpressions(t *testing.T) {
+	db, temporalDB, cleanup := createBatchTestDB(t)
+	defer cleanup()
+
+	// a 3% raise for everyone earning a salary on 1996-06-01
+	ctx := bitemporal.WithValidTime(context.Background(), bitemporal.AsTime("1996-06-01"))
+	results, err := temporalDB.ApplyBatchUpdateWindow(ctx, bitemporal.BatchUpdateWindow{
+		Table:       "salaries",
+		Select:      []string{"emp_no", "salary"},
+		FilterBy:    []string{"emp_no"},
+		KeyQuery:    "SELECT emp_no FROM salaries$",
+		Expressions: map[string]string{"salary": "salary * 103 / 100"},
+		ValidFrom:   bitemporal.AsTime("1996-01-01"),
+		ValidTo:     bitemporal.AsTime("1998-01-01"),
+		TxnMoment:   testTxnMoment,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected results for 10009 and 10010, got %v", results)
+	}
+
+	// 10009 has records changing at 1996-02-16 and 1997-02-15, so its raise spans three periods
+	expected := []SalaryRow{
+		{EmpNo: 10009, Salary: 80944, ValidFrom: "1995-02-16 00:00:00", ValidTo: "1996-01-01 00:00:00"},
+		{EmpNo: 10009, Salary: 83372, ValidFrom: "1996-01-01 00:00:00", ValidTo: "1996-02-16 00:00:00"},
+		{EmpNo: 10009, Salary: 84982, ValidFrom: "1996-02-16 00:00:00", ValidTo: "1997-02-15 00:00:00"},
+		{EmpNo: 10009, Salary: 88451, ValidFrom: "1997-02-15 00:00:00", ValidTo: "1998-01-01 00:00:00"},
+		{EmpNo: 10009, Salary: 85875, ValidFrom: "1998-01-01 00:00:00", ValidTo: "1998-02-15 00:00:00"},
+	}
+	var actual []SalaryRow
+	for _, row := range currentSalaries(t, db, 10009) {
+		if row.ValidFrom >= "1995-02-16" && row.ValidTo <= "1998-02-15 00:00:00" {
+			actual = append(actual, SalaryRow{EmpNo: row.EmpNo, Salary: row.Salary, ValidFrom: row.ValidFrom, ValidTo: row.ValidTo})
+		}
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+
+	// 10011 was not earning on 1996-06-01 and keeps its salary
+	for _, row := range currentSalaries(t, db, 10011) {
+		if row.Salary != 40000 {
+			t.Errorf("Expected 10011 to be untouched, got %v", row)
+		}
+	}
+}
+
+func TestBatchInvertedWindow(t *testing.T) {
+	_, temporalDB, cleanup := createBatchTestDB(t)
+	defer cleanup()
+
+	_, err := temporalDB.ApplyBatchUpdateWindow(context.Background(), bitemporal.BatchUpdateWindow{
+		Table:     "salaries",
+		Select:    []string{"emp_no", "salary"},
+		FilterBy:  []string{"emp_no"},
+		Keys:      []map[string]any{{"emp_no": 10009}, {"emp_no": 10010}},
+		Values:    map[string]any{"salary": 42},
+		ValidFrom: bitemporal.AsTime("1999-01-01"),
+		ValidTo:   bitemporal.AsTime("1995-01-01"),
+		TxnMoment: testTxnMoment,
+	})
+	if err == nil {
+		t.Fatal("Expected a batch window valid from after it is valid to to be rejected")
+	}
+	overlaps, err := temporalDB.Audit(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(overlaps) != 0 {
+		t.Errorf("Expected no overlaps, got %v", overlaps)
+	}
+}
@@ -7,15 +7,21 @@ import (
 
 var EndOfTime time.Time
 
+// StartOfTime is before every moment stored, the earliest DATETIME MySQL accepts. It is what "-infinity" parses to,
+// the zero time would read as unset.
+var StartOfTime time.Time
+
 func init() {
 	EndOfTime, _ = time.Parse(time.DateTime, "9999-12-31 23:59:59")
+	StartOfTime, _ = time.Parse(time.DateTime, "1000-01-01 00:00:00")
 }
 
+// Entity holds the temporal columns of a record, models embed it tagged with their table name, see TableOf
 type Entity struct {
-	ValidOpen  time.Time `json:"valid_open"`
-	ValidClose time.Time `json:"valid_close"`
-	TxnOpen    time.Time `json:"txn_open"`
-	TxnClose   time.Time `json:"txn_close"`
+	ValidOpen  time.Time `json:"valid_open" db:"valid_open"`
+	ValidClose time.Time `json:"valid_close" db:"valid_close"`
+	TxnOpen    time.Time `json:"txn_open" db:"txn_open"`
+	TxnClose   time.Time `json:"txn_close" db:"txn_close"`
 }
 
 func (e Entity) String() string {
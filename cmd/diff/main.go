@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pborges/bitemporal"
+	_ "github.com/pborges/bitemporal/model"
+)
+
+// Lists what changed in a table between two transaction moments, e.g.
+//
+//	go run ./cmd/diff -table salaries -from "2025-08-29 17:00:00" -to now
+func main() {
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+	log.SetOutput(os.Stdout)
+
+	dbPath := flag.String("db", "bitemporal.db", "database to compare")
+	table := flag.String("table", "salaries", "registered table to compare")
+	from := flag.String("from", "", "transaction moment to compare from")
+	to := flag.String("to", "now", "transaction moment to compare to")
+	flag.Parse()
+
+	if *from == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	database, err := bitemporal.OpenSQLite(*dbPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := bitemporal.NewTemporalDB(database)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer db.Close()
+
+	fromMoment, err := bitemporal.ParseTime(*from)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	toMoment, err := bitemporal.ParseTime(*to)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	differences, err := db.Diff(context.Background(), *table, fromMoment, toMoment)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	output := tablewriter.NewWriter(os.Stdout)
+	output.Header([]string{"Key", "Kind", "ValidFrom", "ValidTo", "Old", "New"})
+	for _, d := range differences {
+		output.Append([]string{
+			values(d.Key, nil),
+			string(d.Kind),
+			d.ValidOpen.Format(time.DateTime),
+			d.ValidClose.Format(time.DateTime),
+			values(d.Old, d.Key),
+			values(d.New, d.Key),
+		})
+	}
+	output.Render()
+	fmt.Printf("%d differences\n", len(differences))
+}
+
+// values formats the columns that are not part of the key
+func values(m map[string]any, key map[string]any) string {
+	if m == nil {
+		return "-"
+	}
+	var columns []string
+	for column := range m {
+		if _, ok := key[column]; !ok {
+			columns = append(columns, column)
+		}
+	}
+	slices.Sort(columns)
+
+	parts := make([]string, len(columns))
+	for i, column := range columns {
+		parts[i] = fmt.Sprintf("%s=%v", column, m[column])
+	}
+	return strings.Join(parts, " ")
+}
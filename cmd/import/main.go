@@ -9,7 +9,6 @@ import (
 	"regexp"
 	"time"
 
-	_ "github.com/mattn/go-sqlite3"
 	"github.com/pborges/bitemporal"
 )
 
@@ -19,14 +18,15 @@ var (
 	testDbDir  = "test_db-1.0.7"
 )
 
-var DbEpoch = bitemporal.AsTime("1950-01-01 01:00:00")
-var EndOfTime = bitemporal.AsTime("9999-12-31 23:59:59")
+// the period columns are written in the canonical encoding, see bitemporal.StorageFormat
+var DbEpoch = bitemporal.FormatTime(bitemporal.AsTime("1950-01-01 01:00:00"))
+var EndOfTime = bitemporal.FormatTime(bitemporal.EndOfTime)
 
 func main() {
 	os.Remove(dbPath)
 
 	startTime := time.Now()
-	db, err := sql.Open("sqlite3", dbPath)
+	db, err := bitemporal.OpenSQLite(dbPath, bitemporal.WithDurability(bitemporal.Fast))
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -38,12 +38,6 @@ func main() {
 	}
 	log.Printf("Schema initialization completed in %v", time.Since(schemaStart))
 
-	optimizeStart := time.Now()
-	if err := optimizeDatabase(db); err != nil {
-		log.Fatal(err)
-	}
-	log.Printf("Database optimization completed in %v", time.Since(optimizeStart))
-
 	if err := importEmployees(db); err != nil {
 		log.Fatal(err)
 	}
@@ -118,7 +112,7 @@ func importEmployees(db *sql.DB) error {
 
 	scanner := bufio.NewScanner(file)
 	count := 0
-	now := time.Now()
+	now := bitemporal.FormatTime(time.Now())
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -137,7 +131,7 @@ func importEmployees(db *sql.DB) error {
 			hireDate := match[6]
 
 			// For bitemporal, we set valid_open to hire_date and transaction_time to now
-			_, err := stmt.Exec(empNo, firstName, lastName, bitemporal.AsTime(hireDate), bitemporal.AsTime(birthDate), gender, bitemporal.AsTime(hireDate), EndOfTime, now, EndOfTime)
+			_, err := stmt.Exec(empNo, firstName, lastName, bitemporal.AsTime(hireDate), bitemporal.AsTime(birthDate), gender, bitemporal.FormatTime(bitemporal.AsTime(hireDate)), EndOfTime, now, EndOfTime)
 			if err != nil {
 				log.Printf("Error inserting employee %s: %v", empNo, err)
 				continue
@@ -182,7 +176,7 @@ func importDepartments(db *sql.DB) error {
 	re := regexp.MustCompile(`\('([^']+)','([^']+)'\)`)
 	scanner := bufio.NewScanner(file)
 	count := 0
-	now := time.Now()
+	now := bitemporal.FormatTime(time.Now())
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -233,7 +227,7 @@ func importDeptEmp(db *sql.DB) error {
 	re := regexp.MustCompile(`\((\d+),'([^']+)','([^']+)','([^']+)'\)`)
 	scanner := bufio.NewScanner(file)
 	count := 0
-	now := time.Now()
+	now := bitemporal.FormatTime(time.Now())
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -252,10 +246,10 @@ func importDeptEmp(db *sql.DB) error {
 			if toDate == "9999-01-01" {
 				toDate = EndOfTime
 			} else {
-				toDate = bitemporal.AsTime(toDate.(string))
+				toDate = bitemporal.FormatTime(bitemporal.AsTime(toDate.(string)))
 			}
 
-			_, err := stmt.Exec(empNo, deptNo, bitemporal.AsTime(fromDate), toDate, now, EndOfTime)
+			_, err := stmt.Exec(empNo, deptNo, bitemporal.FormatTime(bitemporal.AsTime(fromDate)), toDate, now, EndOfTime)
 			if err != nil {
 				log.Printf("Error inserting dept_emp %s-%s: %v", empNo, deptNo, err)
 				continue
@@ -288,7 +282,7 @@ func importDeptManager(db *sql.DB) error {
 	re := regexp.MustCompile(`\((\d+),'([^']+)','([^']+)','([^']+)'\)`)
 	scanner := bufio.NewScanner(file)
 	count := 0
-	now := time.Now()
+	now := bitemporal.FormatTime(time.Now())
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -306,10 +300,10 @@ func importDeptManager(db *sql.DB) error {
 			if toDate == "9999-01-01" {
 				toDate = EndOfTime
 			} else {
-				toDate = bitemporal.AsTime(toDate.(string))
+				toDate = bitemporal.FormatTime(bitemporal.AsTime(toDate.(string)))
 			}
 
-			_, err := stmt.Exec(empNo, deptNo, bitemporal.AsTime(fromDate), toDate, now, EndOfTime)
+			_, err := stmt.Exec(empNo, deptNo, bitemporal.FormatTime(bitemporal.AsTime(fromDate)), toDate, now, EndOfTime)
 			if err != nil {
 				log.Printf("Error inserting dept_manager %s-%s: %v", empNo, deptNo, err)
 				continue
@@ -342,7 +336,7 @@ func importTitles(db *sql.DB) error {
 	re := regexp.MustCompile(`\((\d+),'([^']+)','([^']+)','([^']+)'\)`)
 	scanner := bufio.NewScanner(file)
 	count := 0
-	now := time.Now()
+	now := bitemporal.FormatTime(time.Now())
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -360,10 +354,10 @@ func importTitles(db *sql.DB) error {
 			if toDate == "9999-01-01" {
 				toDate = EndOfTime
 			} else {
-				toDate = bitemporal.AsTime(toDate.(string))
+				toDate = bitemporal.FormatTime(bitemporal.AsTime(toDate.(string)))
 			}
 
-			_, err := stmt.Exec(empNo, title, bitemporal.AsTime(fromDate), toDate, now, EndOfTime)
+			_, err := stmt.Exec(empNo, title, bitemporal.FormatTime(bitemporal.AsTime(fromDate)), toDate, now, EndOfTime)
 			if err != nil {
 				log.Printf("Error inserting title %s-%s: %v", empNo, title, err)
 				continue
@@ -395,7 +389,7 @@ func importSalaries(db *sql.DB) error {
 
 	re := regexp.MustCompile(`\((\d+),(\d+),'([^']+)','([^']+)'\)`)
 	totalCount := 0
-	now := time.Now()
+	now := bitemporal.FormatTime(time.Now())
 
 	for _, filename := range files {
 		file, err := os.Open(filename)
@@ -422,10 +416,10 @@ func importSalaries(db *sql.DB) error {
 				if toDate == "9999-01-01" {
 					toDate = EndOfTime
 				} else {
-					toDate = bitemporal.AsTime(toDate.(string))
+					toDate = bitemporal.FormatTime(bitemporal.AsTime(toDate.(string)))
 				}
 
-				_, err := stmt.Exec(empNo, salary, bitemporal.AsTime(fromDate), toDate, now, EndOfTime)
+				_, err := stmt.Exec(empNo, salary, bitemporal.FormatTime(bitemporal.AsTime(fromDate)), toDate, now, EndOfTime)
 				if err != nil {
 					log.Printf("Error inserting salary %s: %v", empNo, err)
 					continue
@@ -442,21 +436,3 @@ func importSalaries(db *sql.DB) error {
 	log.Printf("Imported %d total salary records in %v", totalCount, time.Since(startTime))
 	return nil
 }
-
-func optimizeDatabase(db *sql.DB) error {
-	pragmas := []string{
-		"PRAGMA journal_mode = MEMORY",
-		"PRAGMA synchronous = OFF",
-		"PRAGMA cache_size = 100000",
-		"PRAGMA temp_store = MEMORY",
-		"PRAGMA locking_mode = EXCLUSIVE",
-	}
-
-	for _, pragma := range pragmas {
-		if _, err := db.Exec(pragma); err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
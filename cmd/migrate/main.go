@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/pborges/bitemporal"
+	_ "github.com/pborges/bitemporal/model"
+)
+
+// Rewrites the period columns of a database written before the canonical encoding, see bitemporal.StorageFormat
+//
+//	go run ./cmd/migrate -db bitemporal.db
+func main() {
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+	log.SetOutput(os.Stdout)
+
+	dbPath := flag.String("db", "bitemporal.db", "database to migrate")
+	flag.Parse()
+
+	database, err := bitemporal.OpenSQLite(*dbPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := bitemporal.NewTemporalDB(database, bitemporal.WithRegistry(bitemporal.DefaultRegistry))
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer db.Close()
+
+	migrated, err := db.MigratePeriods(context.Background())
+	if err != nil {
+		log.Fatalln(err)
+	}
+	log.Printf("migrated %d records", migrated)
+}
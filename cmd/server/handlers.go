@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pborges/bitemporal"
+	"github.com/pborges/bitemporal/model"
+)
+
+type server struct {
+	db        *bitemporal.TemporalDB
+	employees *model.EmployeeRepository
+	salaries  *model.SalaryRepository
+	titles    *model.TitleRepository
+	mux       *http.ServeMux
+	handler   http.Handler
+}
+
+func newServer(db *bitemporal.TemporalDB) *server {
+	s := &server{
+		db:        db,
+		employees: model.NewEmployeeRepository(db),
+		salaries:  model.NewSalaryRepository(db),
+		titles:    model.NewTitleRepository(db),
+		mux:       http.NewServeMux(),
+	}
+	s.mux.HandleFunc("GET /employees/{emp_no}", s.getEmployee)
+	s.mux.HandleFunc("GET /employees/{emp_no}/history", s.getHistory)
+	s.mux.HandleFunc("GET /employees/{emp_no}/salaries", getPeriods(s.salaries.Repository))
+	s.mux.HandleFunc("GET /employees/{emp_no}/titles", getPeriods(s.titles.Repository))
+	s.mux.HandleFunc("PUT /employees/{emp_no}/salary", putWindow(s.salaries.Repository, func(m *model.Salary, empNo int64) {
+		m.EmpNo = empNo
+	}))
+	s.mux.HandleFunc("PUT /employees/{emp_no}/title", putWindow(s.titles.Repository, func(m *model.Title, empNo int64) {
+		m.EmpNo = empNo
+	}))
+	s.handler = bitemporal.HTTPMiddleware(s.mux)
+	return s
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(w, r)
+}
+
+// getEmployee returns the employee valid at valid_at as known at known_at, both default to now, see HTTPMiddleware
+func (s *server) getEmployee(w http.ResponseWriter, r *http.Request) {
+	empNo, ctx, ok := parseRequest(w, r)
+	if !ok {
+		return
+	}
+	employee, err := s.employees.ById(ctx, empNo)
+	if errors.Is(err, sql.ErrNoRows) {
+		bitemporal.WriteError(w, http.StatusNotFound, fmt.Errorf("employee %d not found", empNo))
+		return
+	}
+	if err != nil {
+		bitemporal.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, employee)
+}
+
+// getPeriods returns the records valid at valid_at as known at known_at, without valid_at every record is returned.
+// The X-Version header holds the version to send back as expected_version when writing, see putWindow.
+func getPeriods[T any](repo *bitemporal.Repository[T]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		empNo, ctx, ok := parseRequest(w, r)
+		if !ok {
+			return
+		}
+		if !bitemporal.HasValidTime(r) {
+			ctx = bitemporal.WithValidRange(ctx, time.Time{}, bitemporal.EndOfTime)
+		}
+		periods, err := repo.List(ctx, empNo)
+		if err != nil {
+			bitemporal.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		version, err := repo.Version(ctx, empNo)
+		if err != nil {
+			bitemporal.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("X-Version", version.Format(time.RFC3339Nano))
+		writeJSON(w, http.StatusOK, nonNil(periods))
+	}
+}
+
+// windowRequest is the valid-time window of a write, its values are decoded from the same body into the model
+type windowRequest struct {
+	ValidFrom string `json:"valid_from"`
+	ValidTo   string `json:"valid_to"`
+	// ExpectedVersion rejects the write with a 409 unless nothing was written for the employee since this version
+	ExpectedVersion string `json:"expected_version"`
+}
+
+type windowResponse[T any] struct {
+	Version time.Time `json:"version"`
+	Periods []T       `json:"periods"`
+}
+
+// putWindow records the values in the body as valid over [valid_from, valid_to), valid_to defaults to the end of
+// time. It responds with the records written and the new version of the employee.
+func putWindow[T any](repo *bitemporal.Repository[T], setKey func(m *T, empNo int64)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		empNo, ctx, ok := parseRequest(w, r)
+		if !ok {
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+		if err != nil {
+			bitemporal.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		var m T
+		var req windowRequest
+		if err = errors.Join(json.Unmarshal(body, &m), json.Unmarshal(body, &req)); err != nil {
+			bitemporal.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		setKey(&m, empNo)
+
+		from, to, version, err := req.parse()
+		if err != nil {
+			bitemporal.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		periods, err := repo.SaveIfVersion(ctx, m, from, to, version)
+		if errors.Is(err, bitemporal.ErrVersionConflict) {
+			bitemporal.WriteError(w, http.StatusConflict, err)
+			return
+		}
+		if err != nil {
+			bitemporal.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		version, err = repo.Version(ctx, empNo)
+		if err != nil {
+			bitemporal.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, windowResponse[T]{Version: version, Periods: periods})
+	}
+}
+
+func (req windowRequest) parse() (from, to, version time.Time, err error) {
+	if req.ValidFrom == "" {
+		return from, to, version, errors.New("valid_from is required")
+	}
+	if from, err = bitemporal.ParseTime(req.ValidFrom); err != nil {
+		return from, to, version, fmt.Errorf("invalid valid_from: %w", err)
+	}
+	to = bitemporal.EndOfTime
+	if req.ValidTo != "" {
+		if to, err = bitemporal.ParseTime(req.ValidTo); err != nil {
+			return from, to, version, fmt.Errorf("invalid valid_to: %w", err)
+		}
+	}
+	if !from.Before(to) {
+		return from, to, version, errors.New("valid_from must be before valid_to")
+	}
+	if req.ExpectedVersion != "" {
+		if version, err = bitemporal.ParseTime(req.ExpectedVersion); err != nil {
+			return from, to, version, fmt.Errorf("invalid expected_version: %w", err)
+		}
+	}
+	return from, to, version, nil
+}
+
+// getHistory returns every change in belief about the employee, see TemporalDB.History
+func (s *server) getHistory(w http.ResponseWriter, r *http.Request) {
+	empNo, ctx, ok := parseRequest(w, r)
+	if !ok {
+		return
+	}
+	changes, err := s.db.History(ctx, s.employees.Table().Name, map[string]any{"emp_no": empNo})
+	if err != nil {
+		bitemporal.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, nonNil(changes))
+}
+
+// parseRequest reads the emp_no path value, the TemporalContext is built by HTTPMiddleware. It writes a 400 and
+// returns false when emp_no is malformed.
+func parseRequest(w http.ResponseWriter, r *http.Request) (int64, context.Context, bool) {
+	empNo, err := strconv.ParseInt(r.PathValue("emp_no"), 10, 64)
+	if err != nil {
+		bitemporal.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid emp_no %q", r.PathValue("emp_no")))
+		return 0, nil, false
+	}
+	return empNo, r.Context(), true
+}
+
+// nonNil makes empty results encode as [] rather than null
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Println(err)
+	}
+}
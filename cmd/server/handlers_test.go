@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pborges/bitemporal"
+	"github.com/pborges/bitemporal/model"
+)
+
+// newTestServer serves an in-memory database holding the schema and test data of sql/
+func newTestServer(t *testing.T) *server {
+	database, err := sql.Open(bitemporal.SQLiteDriver, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every connection to :memory: is a new database
+	database.SetMaxOpenConns(1)
+	for _, file := range []string{"schema.sql", "test_window_data.sql", "test_valid_time_data.sql"} {
+		script, err := os.ReadFile(filepath.Join("..", "..", "sql", file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = database.Exec(string(script)); err != nil {
+			t.Fatalf("Failed to execute %s: %v", file, err)
+		}
+	}
+
+	db, err := bitemporal.NewTemporalDB(database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	// the test data predates the canonical encoding
+	if _, err = db.MigratePeriods(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return newServer(db)
+}
+
+// serve sends the request to the server and decodes a JSON response into v
+func serve(t *testing.T, s *server, method, target, body string, v any) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+	if v != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatalf("%s %s: failed to decode %q: %v", method, target, rec.Body.String(), err)
+		}
+	}
+	return rec
+}
+
+func TestGetEmployee(t *testing.T) {
+	s := newTestServer(t)
+
+	// the marriage on 2023-06-15 was recorded on 2023-07-01, and corrected to 2023-06-10 on 2023-08-15
+	for _, test := range []struct {
+		target   string
+		lastName string
+	}{
+		{"/employees/12345?valid_at=2023-06-16&known_at=2023-07-05T23:59:59Z", "Johnson"},
+		{"/employees/12345?valid_at=2023-06-12&known_at=2023-06-20T23:59:59Z", "Smith"},
+		{"/employees/12345?valid_at=2023-06-12", "Johnson"},
+		{"/employees/12345?valid_at=2020-02-01", "Smith"},
+	} {
+		var employee model.Employee
+		rec := serve(t, s, http.MethodGet, test.target, "", &employee)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s: expected 200, got %d %s", test.target, rec.Code, rec.Body)
+		}
+		if employee.EmpNo != 12345 || employee.LastName != test.lastName {
+			t.Errorf("GET %s: expected %s, got %s", test.target, test.lastName, employee)
+		}
+	}
+}
+
+func TestGetPeriods(t *testing.T) {
+	s := newTestServer(t)
+
+	var salaries []model.Salary
+	rec := serve(t, s, http.MethodGet, "/employees/10009/salaries", "", &salaries)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d %s", rec.Code, rec.Body)
+	}
+	if len(salaries) != 18 {
+		t.Errorf("Expected every one of the 18 salaries without valid_at, got %d", len(salaries))
+	}
+
+	rec = serve(t, s, http.MethodGet, "/employees/10009/salaries?valid_at=1996-01-01", "", &salaries)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d %s", rec.Code, rec.Body)
+	}
+	if len(salaries) != 1 || salaries[0].Salary != 80944 {
+		t.Errorf("Expected only the salary valid on 1996-01-01, got %v", salaries)
+	}
+
+	// nothing was known about the salaries before the test data was recorded
+	rec = serve(t, s, http.MethodGet, "/employees/10009/salaries?known_at=2025-01-01", "", &salaries)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d %s", rec.Code, rec.Body)
+	}
+	if len(salaries) != 0 {
+		t.Errorf("Expected no salaries known on 2025-01-01, got %v", salaries)
+	}
+}
+
+func TestGetHistory(t *testing.T) {
+	s := newTestServer(t)
+
+	var changes []bitemporal.BeliefChange
+	rec := serve(t, s, http.MethodGet, "/employees/12345/history", "", &changes)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d %s", rec.Code, rec.Body)
+	}
+	if len(changes) != 5 {
+		t.Fatalf("Expected 5 belief changes, got %v", changes)
+	}
+	if last := changes[len(changes)-1]; last.Old != nil || last.New["last_name"] != "Johnson" {
+		t.Errorf("Expected the last change to record Johnson from the corrected marriage date, got %v", last)
+	}
+
+	rec = serve(t, s, http.MethodGet, "/employees/99999/history", "", &changes)
+	if rec.Code != http.StatusOK || len(changes) != 0 {
+		t.Errorf("Expected an empty history for an unknown employee, got %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	s := newTestServer(t)
+
+	for _, test := range []struct {
+		target string
+		status int
+	}{
+		{"/employees/99999", http.StatusNotFound},
+		{"/employees/12345?valid_at=1999-01-01", http.StatusNotFound},
+		{"/employees/abc", http.StatusBadRequest},
+		{"/employees/abc/salaries", http.StatusBadRequest},
+		{"/employees/12345?valid_at=yesterday", http.StatusBadRequest},
+		{"/employees/10009/salaries?known_at=2025-13-01", http.StatusBadRequest},
+		{"/employees/12345/history?known_at=soon", http.StatusBadRequest},
+	} {
+		var body map[string]string
+		rec := serve(t, s, http.MethodGet, test.target, "", &body)
+		if rec.Code != test.status {
+			t.Errorf("GET %s: expected %d, got %d %s", test.target, test.status, rec.Code, rec.Body)
+		}
+		if body["error"] == "" {
+			t.Errorf("GET %s: expected a JSON error, got %s", test.target, rec.Body)
+		}
+	}
+}
+
+func TestPutWindow(t *testing.T) {
+	s := newTestServer(t)
+
+	var salaries []model.Salary
+	rec := serve(t, s, http.MethodGet, "/employees/10009/salaries", "", &salaries)
+	version := rec.Header().Get("X-Version")
+	if _, err := bitemporal.ParseTime(version); err != nil {
+		t.Fatalf("Expected an X-Version header, got %q", version)
+	}
+
+	body := `{"salary": 42, "valid_from": "1995-01-01", "valid_to": "2000-01-01", "expected_version": "` + version + `"}`
+	var written windowResponse[model.Salary]
+	rec = serve(t, s, http.MethodPut, "/employees/10009/salary", body, &written)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d %s", rec.Code, rec.Body)
+	}
+	// salary 42 is written over every salary the window overlaps, the periods must tile the window
+	cursor := bitemporal.AsTime("1995-01-01")
+	for _, period := range written.Periods {
+		if period.EmpNo != 10009 {
+			t.Errorf("Expected the key to be taken from the path, got %s", period)
+		}
+		if period.Salary == 42 {
+			if !period.ValidOpen.Equal(cursor) {
+				t.Errorf("Expected salary 42 to continue at %s, got %s", cursor, period.Entity)
+			}
+			cursor = period.ValidClose
+		}
+	}
+	if !cursor.Equal(bitemporal.AsTime("2000-01-01")) {
+		t.Errorf("Expected salary 42 to be written until 2000-01-01, got %v", written.Periods)
+	}
+
+	rec = serve(t, s, http.MethodGet, "/employees/10009/salaries", "", &salaries)
+	if newVersion := rec.Header().Get("X-Version"); newVersion == version || !bitemporal.AsTime(newVersion).Equal(written.Version) {
+		t.Errorf("Expected the X-Version header to move to the version of the write %s, got %s", written.Version, newVersion)
+	}
+
+	// a second writer still holding the old version
+	var conflict map[string]string
+	rec = serve(t, s, http.MethodPut, "/employees/10009/salary", body, &conflict)
+	if rec.Code != http.StatusConflict || conflict["error"] == "" {
+		t.Errorf("Expected a 409 for a stale expected_version, got %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestPutWindowBadRequests(t *testing.T) {
+	s := newTestServer(t)
+
+	for _, body := range []string{
+		`{"salary": 42, "valid_from": "1995-01-01"`,
+		`{"salary": "42", "valid_from": "1995-01-01"}`,
+		`{"salary": 42}`,
+		`{"salary": 42, "valid_from": "someday"}`,
+		`{"salary": 42, "valid_from": "2000-01-01", "valid_to": "1995-01-01"}`,
+		`{"salary": 42, "valid_from": "1995-01-01", "expected_version": "v1"}`,
+	} {
+		var response map[string]string
+		rec := serve(t, s, http.MethodPut, "/employees/10009/salary", body, &response)
+		if rec.Code != http.StatusBadRequest || response["error"] == "" {
+			t.Errorf("PUT %s: expected a 400 with a JSON error, got %d %s", body, rec.Code, rec.Body)
+		}
+	}
+}
@@ -1,24 +1,27 @@
 package main
 
 import (
-	"context"
-	"database/sql"
-	"fmt"
+	"flag"
 	"log"
+	"net/http"
 	"os"
-	"time"
 
-	_ "github.com/mattn/go-sqlite3"
 	"github.com/pborges/bitemporal"
-	"github.com/pborges/bitemporal/model"
 )
 
-// This file is just a scratch pad for now
+// Serves the temporal reads of the HR database over HTTP, e.g.
+//
+//	go run ./cmd/server -addr :8080
+//	curl 'localhost:8080/employees/10009/salaries?known_at=2025-08-29'
 func main() {
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 	log.SetOutput(os.Stdout)
 
-	database, err := sql.Open("sqlite3", "bitemporal.db")
+	addr := flag.String("addr", ":8080", "address to listen on")
+	dbPath := flag.String("db", "bitemporal.db", "database to serve")
+	flag.Parse()
+
+	database, err := bitemporal.OpenSQLite(*dbPath)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -29,38 +32,6 @@ func main() {
 	}
 	defer db.Close()
 
-	employeesRepo := model.NewEmployeeRepository(db)
-	salariesRepo := model.NewSalaryRepository(db)
-
-	dump(employeesRepo, salariesRepo, 10009, time.Now())
-	dump(employeesRepo, salariesRepo, 10009, bitemporal.AsTime("1993-02-17"))
-
-	fmt.Println("All Salaries")
-	ctx := bitemporal.WithValidTime(context.Background(), time.Time{})
-	salaries, err := salariesRepo.ForEmployee(ctx, 10009)
-	if err != nil {
-		log.Fatalln(err)
-	}
-	for _, salary := range salaries {
-		fmt.Printf("  $%d %+v\n", salary.Salary, salary.Entity)
-	}
-}
-
-func dump(employeesRepo *model.EmployeeRepository, salariesRepo *model.SalaryRepository, empNo int64, asOfValid time.Time) {
-	fmt.Println("* DUMPING AS OF: ", asOfValid.Format(time.DateTime))
-	ctx := bitemporal.InitializeContext(context.Background())
-	ctx = bitemporal.WithValidTime(ctx, asOfValid)
-	employee, err := employeesRepo.ById(ctx, empNo)
-	if err != nil {
-		log.Fatalln(err)
-	}
-	salaries, err := salariesRepo.ForEmployee(ctx, employee.EmpNo)
-	if err != nil {
-		log.Fatalln(err)
-	}
-
-	fmt.Printf("Employee:\n  %s %s %s\nSalaries:\n", employee.FirstName, employee.LastName, employee.Entity)
-	for _, salary := range salaries {
-		fmt.Printf("  $%d %+v\n", salary.Salary, salary.Entity)
-	}
+	log.Printf("listening on %s", *addr)
+	log.Fatalln(http.ListenAndServe(*addr, newServer(db)))
 }
@@ -10,6 +10,14 @@ const temporalContextKey = "TemporalContextKey"
 type TemporalContext struct {
 	ValidMoment  time.Time
 	SystemMoment time.Time
+
+	// TxnMoment records writes at a given transaction moment instead of the Clock of the TemporalDB, it is never
+	// taken from SystemMoment, which only selects what is read
+	TxnMoment time.Time
+
+	// ValidRangeOpen and ValidRangeClose select every version overlapping [open, close) instead of a single ValidMoment
+	ValidRangeOpen  time.Time
+	ValidRangeClose time.Time
 }
 
 // InitializeContext if there is no temporalContext set it to now now
@@ -28,9 +36,27 @@ func GetValidMoment(ctx context.Context) time.Time {
 	return time.Time{}
 }
 
+// WithValidTime replaces any valid range set on the context
 func WithValidTime(ctx context.Context, moment time.Time) context.Context {
 	t, _ := ctx.Value(temporalContextKey).(TemporalContext)
 	t.ValidMoment = moment
+	t.ValidRangeOpen, t.ValidRangeClose = time.Time{}, time.Time{}
+	return context.WithValue(ctx, temporalContextKey, t)
+}
+
+// GetValidRange returns the valid range of the context, ok is false when none is set
+func GetValidRange(ctx context.Context) (from, to time.Time, ok bool) {
+	if t, ok := ctx.Value(temporalContextKey).(TemporalContext); ok && !t.ValidRangeClose.IsZero() {
+		return t.ValidRangeOpen, t.ValidRangeClose, true
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// WithValidRange selects every version valid at some point in [from, to), it replaces any valid moment set on the context
+func WithValidRange(ctx context.Context, from, to time.Time) context.Context {
+	t, _ := ctx.Value(temporalContextKey).(TemporalContext)
+	t.ValidMoment = time.Time{}
+	t.ValidRangeOpen, t.ValidRangeClose = from, to
 	return context.WithValue(ctx, temporalContextKey, t)
 }
 
@@ -46,3 +72,19 @@ func WithSystemMoment(ctx context.Context, moment time.Time) context.Context {
 	t.SystemMoment = moment
 	return context.WithValue(ctx, temporalContextKey, t)
 }
+
+// GetTxnMoment returns the transaction moment writes of the context are recorded at, or the zero time when the Clock
+// of the TemporalDB decides
+func GetTxnMoment(ctx context.Context) time.Time {
+	if t, ok := ctx.Value(temporalContextKey).(TemporalContext); ok {
+		return t.TxnMoment
+	}
+	return time.Time{}
+}
+
+// WithTxnMoment records the writes of the context at moment, for replaying and back-filling
+func WithTxnMoment(ctx context.Context, moment time.Time) context.Context {
+	t, _ := ctx.Value(temporalContextKey).(TemporalContext)
+	t.TxnMoment = moment
+	return context.WithValue(ctx, temporalContextKey, t)
+}
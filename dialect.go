@@ -0,0 +1,186 @@
+package bitemporal
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Dialect adapts the SQL a TemporalDB generates to a database. Queries, including the update window templates,
+// are written with @name parameters and the Dialect binds them in its own placeholder style.
+type Dialect interface {
+	// Name identifies the dialect, e.g. "sqlite"
+	Name() string
+	// Bind rewrites the @name parameters of query into the placeholders of the dialect and returns the arguments to
+	// pass along with it. Parameters missing from args are bound as NULL.
+	Bind(query string, args map[string]any) (string, []any)
+	// Timestamp renders a moment as an SQL literal in the encoding of the period columns
+	Timestamp(t time.Time) string
+	// Infinity is the literal closing periods that have not ended, the Timestamp of EndOfTime
+	Infinity() string
+	// ViewName names the temporal view of table, e.g. salaries$, see TemporalDB.View
+	ViewName(table string) string
+	// Session lists the statements that set up a connection
+	Session() []string
+	// ColumnsQuery lists the column names of the table given as @table, see Registry.Validate
+	ColumnsQuery() string
+}
+
+// SQLite is the reference Dialect: @name parameters are bound as sql.Named, and moments are stored as text in
+// StorageFormat so they compare as plain strings
+var SQLite Dialect = sqliteDialect{}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string {
+	return "sqlite"
+}
+
+// Bind keeps the @name parameters, the arguments are ordered by name and times are encoded in StorageFormat. SQLite
+// binds missing arguments as NULL.
+func (sqliteDialect) Bind(query string, args map[string]any) (string, []any) {
+	bound := make([]any, 0, len(args))
+	for _, name := range slices.Sorted(maps.Keys(args)) {
+		bound = append(bound, sql.Named(name, storageValue(args[name])))
+	}
+	return query, bound
+}
+
+func (sqliteDialect) Timestamp(t time.Time) string {
+	return "'" + FormatTime(t) + "'"
+}
+
+func (d sqliteDialect) Infinity() string {
+	return d.Timestamp(EndOfTime)
+}
+
+func (sqliteDialect) ViewName(table string) string {
+	return table + "$"
+}
+
+// Session is empty, the pragmas are run on every connection by OpenSQLite
+func (sqliteDialect) Session() []string {
+	return nil
+}
+
+func (sqliteDialect) ColumnsQuery() string {
+	return "SELECT name FROM pragma_table_info(@table)"
+}
+
+// the SQLite types and defaults of sql/schema.sql, translated by the DDL of the other dialects
+var (
+	sqliteIdentity = regexp.MustCompile(`INTEGER(\s+)NOT NULL PRIMARY KEY AUTOINCREMENT`)
+	sqliteInteger  = regexp.MustCompile(`\bINTEGER\b`)
+	sqliteDatetime = regexp.MustCompile(`\bDATETIME\b`)
+	sqliteNow      = regexp.MustCompile(`DEFAULT \(STRFTIME\('[^']*'\)\)`)
+	sqliteMoment   = regexp.MustCompile(`'\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{6}Z'`)
+)
+
+// replaceMoments rewrites the moments quoted in StorageFormat with the literal of timestamp
+func replaceMoments(schema string, timestamp func(time.Time) string) (string, error) {
+	var err error
+	schema = sqliteMoment.ReplaceAllStringFunc(schema, func(literal string) string {
+		t, parseErr := ParseTime(strings.Trim(literal, "'"))
+		if parseErr != nil {
+			err = parseErr
+			return literal
+		}
+		return timestamp(t)
+	})
+	return schema, err
+}
+
+// bindPositional rewrites every @name parameter outside of string literals and comments with the placeholder for its 1-based
+// position and returns the arguments in the order of the positions. A repeated name keeps the position of its first
+// use unless repeat is set, in which case it is bound again.
+func bindPositional(query string, args map[string]any, repeat bool, placeholder func(name string, n int) string) (string, []any) {
+	var b strings.Builder
+	var bound []any
+	positions := make(map[string]int)
+	quoted := false
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		if c == '\'' {
+			quoted = !quoted
+		}
+		if c == '-' && !quoted && strings.HasPrefix(query[i:], "--") {
+			// comments run to the end of the line, they may hold quotes and @
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			b.WriteString(query[i : i+end])
+			i += end - 1
+			continue
+		}
+		if c != '@' || quoted || i+1 == len(query) || !isIdentStart(query[i+1]) {
+			b.WriteByte(c)
+			continue
+		}
+		j := i + 1
+		for j < len(query) && isIdentChar(query[j]) {
+			j++
+		}
+		name := query[i+1 : j]
+		n, ok := positions[name]
+		if !ok || repeat {
+			bound = append(bound, args[name])
+			n = len(bound)
+			positions[name] = n
+		}
+		b.WriteString(placeholder(name, n))
+		i = j - 1
+	}
+	return b.String(), bound
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || '0' <= c && c <= '9'
+}
+
+// derivedTables is implemented by a Dialect that may lack common table expressions
+type derivedTables interface {
+	// derivedViews reports whether the temporal views are rendered as derived tables instead of a WITH clause
+	derivedViews() bool
+}
+
+// bind renders the fragment for the dialect of the TemporalDB
+func (repo *TemporalDB) bind(fragment QueryFragment) (string, []any) {
+	return repo.dialect.Bind(fragment.Query, fragment.ArgMap)
+}
+
+// ErrUnsupported is returned by the operations still written against SQLite when the TemporalDB has another Dialect
+var ErrUnsupported = errors.New("unsupported by dialect")
+
+// requireSQLite fails operation with ErrUnsupported unless the dialect of the TemporalDB is SQLite
+func (repo *TemporalDB) requireSQLite(operation string) error {
+	if repo.dialect.Name() != SQLite.Name() {
+		return fmt.Errorf("%s is %w %s", operation, ErrUnsupported, repo.dialect.Name())
+	}
+	return nil
+}
+
+// greatest renders the later of two moments, SQLite overloads MAX with the scalar form
+func (repo *TemporalDB) greatest(a, b string) string {
+	if repo.dialect.Name() == SQLite.Name() {
+		return "MAX(" + a + ", " + b + ")"
+	}
+	return "GREATEST(" + a + ", " + b + ")"
+}
+
+// least renders the earlier of two moments, SQLite overloads MIN with the scalar form
+func (repo *TemporalDB) least(a, b string) string {
+	if repo.dialect.Name() == SQLite.Name() {
+		return "MIN(" + a + ", " + b + ")"
+	}
+	return "LEAST(" + a + ", " + b + ")"
+}
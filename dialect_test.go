@@ -0,0 +1,257 @@
+package bitemporal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// goldenDialects lists the dialects whose generated SQL is kept in testdata/golden/<name>
+var goldenDialects = []Dialect{SQLite, Postgres, MySQL}
+
+var goldenTables = []Table{
+	{Name: "salaries", Columns: []string{"emp_no", "salary"}, Keys: []string{"emp_no"}},
+	{Name: "titles", Columns: []string{"emp_no", "title"}, Keys: []string{"emp_no"}},
+}
+
+func TestDialectGolden(t *testing.T) {
+	validMoment := time.Date(1995, 6, 1, 0, 0, 0, 0, time.UTC)
+	txnMoment := time.Date(2025, 9, 1, 12, 0, 0, 123456000, time.UTC)
+	window := UpdateWindow{
+		Table:     "salaries",
+		Select:    []string{"emp_no", "salary"},
+		FilterBy:  []string{"emp_no"},
+		ValidFrom: time.Date(1995, 1, 1, 0, 0, 0, 0, time.UTC),
+		ValidTo:   time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		TxnMoment: txnMoment,
+		Values:    map[string]any{"emp_no": 10009, "salary": 42},
+	}
+	coalesce := window
+	coalesce.Coalesce = true
+	batch := BatchUpdateWindow{
+		Table:     "salaries",
+		Select:    []string{"emp_no", "salary"},
+		FilterBy:  []string{"emp_no"},
+		Keys:      []map[string]any{{"emp_no": 10009}},
+		Values:    map[string]any{"salary": 42},
+		ValidFrom: window.ValidFrom,
+		ValidTo:   window.ValidTo,
+		TxnMoment: txnMoment,
+	}
+
+	for _, dialect := range goldenDialects {
+		repo := &TemporalDB{dialect: dialect, temporalTables: goldenTables}
+		queries := map[string]func() (QueryFragment, error){
+			"as_of": func() (QueryFragment, error) {
+				ctx := WithSystemMoment(WithValidTime(context.Background(), validMoment), txnMoment)
+				return repo.prepareQuery(ctx, QueryFragment{"SELECT * FROM " + repo.View("salaries", "") + " WHERE emp_no = @emp_no", map[string]any{"emp_no": 10009}}), nil
+			},
+			"valid_range": func() (QueryFragment, error) {
+				ctx := WithValidRange(context.Background(), window.ValidFrom, window.ValidTo)
+				return repo.prepareQuery(ctx, QueryFragment{"SELECT * FROM " + repo.View("titles", ""), nil}), nil
+			},
+			"update_window": func() (QueryFragment, error) {
+				return createPeriodsQuery(dialect, window)
+			},
+			"coalesce_window": func() (QueryFragment, error) {
+				return createPeriodsQuery(dialect, coalesce)
+			},
+			"close_window": func() (QueryFragment, error) {
+				return renderWindowQuery(dialect, closeUpdateWindowQuery, window)
+			},
+			"join": func() (QueryFragment, error) {
+				fragment, err := repo.JoinQuery(Join{Left: "salaries", Right: "titles"})
+				ctx := WithSystemMoment(WithValidTime(context.Background(), validMoment), txnMoment)
+				return repo.prepareQuery(ctx, fragment), err
+			},
+		}
+		// batch update windows are only applied by SQLite, see ErrUnsupported
+		if dialect == SQLite {
+			queries["batch_update_window"] = func() (QueryFragment, error) {
+				return renderBatchQuery(dialect, createBatchUpdateWindowQuery, batch)
+			}
+			queries["close_batch_window"] = func() (QueryFragment, error) {
+				return renderBatchQuery(dialect, closeBatchUpdateWindowQuery, batch)
+			}
+		}
+
+		for name, render := range queries {
+			t.Run(dialect.Name()+"/"+name, func(t *testing.T) {
+				fragment, err := render()
+				if err != nil {
+					t.Fatal(err)
+				}
+				checkGolden(t, filepath.Join("testdata", "golden", dialect.Name(), name+".sql"), goldenSQL(repo.bind(fragment)))
+			})
+		}
+	}
+}
+
+func TestUnsupportedByDialect(t *testing.T) {
+	ctx := context.Background()
+	for _, dialect := range []Dialect{Postgres, MySQL} {
+		repo := &TemporalDB{dialect: dialect, temporalTables: goldenTables}
+		operations := map[string]func() error{
+			"aggregate every": func() error {
+				_, err := repo.Aggregate(ctx, Aggregation{
+					Table:      "salaries",
+					Expression: "COUNT(t.emp_no)",
+					ValidFrom:  time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+					ValidTo:    time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC),
+					Every:      "+1 month",
+				})
+				return err
+			},
+			"audit": func() error {
+				_, err := repo.Audit(ctx)
+				return err
+			},
+			"overlap check": func() error {
+				return OverlapCheck(ctx, repo, nil, Write{Table: "salaries"})
+			},
+			"batch update window": func() error {
+				_, err := repo.ApplyBatchUpdateWindow(ctx, BatchUpdateWindow{Table: "salaries"})
+				return err
+			},
+			"migrate periods": func() error {
+				_, err := repo.MigratePeriods(ctx)
+				return err
+			},
+		}
+		for name, operation := range operations {
+			if err := operation(); !errors.Is(err, ErrUnsupported) {
+				t.Errorf("Expected %s to be unsupported by the %s dialect, got %v", name, dialect.Name(), err)
+			}
+		}
+	}
+}
+
+func TestPostgresDDL(t *testing.T) {
+	schema, err := os.ReadFile(filepath.Join("sql", "schema.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, err := NewRegistry(goldenTables...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, dialect := range map[string]PostgresDialect{"schema": Postgres, "schema_ranges": {Ranges: true}} {
+		t.Run(name, func(t *testing.T) {
+			ddl, err := dialect.DDL(string(schema), registry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, filepath.Join("testdata", "golden", "postgres", name+".sql"), ddl)
+		})
+	}
+}
+
+func TestSQLiteBind(t *testing.T) {
+	moment := time.Date(2025, 9, 1, 12, 0, 0, 0, time.FixedZone("PDT", -7*60*60))
+	_, args := SQLite.Bind("SELECT * FROM employees WHERE hire_date = @hire_date AND valid_open <= @valid_open",
+		map[string]any{"hire_date": moment, "valid_open": moment})
+
+	expected := []any{
+		sql.Named("hire_date", "2025-09-01T19:00:00.000000Z"),
+		sql.Named("valid_open", "2025-09-01T19:00:00.000000Z"),
+	}
+	if fmt.Sprint(args) != fmt.Sprint(expected) {
+		t.Errorf("expected every time to be encoded, %v, got %v", expected, args)
+	}
+}
+
+func TestPostgresBind(t *testing.T) {
+	moment := time.Date(2025, 9, 1, 12, 0, 0, 0, time.FixedZone("PDT", -7*60*60))
+	query, args := Postgres.Bind("SELECT @a, 'it''s @a', @b -- isn't @b\nFROM t WHERE x = @a AND y = @missing",
+		map[string]any{"a": moment, "b": 1})
+
+	expected := "SELECT $1::timestamptz, 'it''s @a', $2::bigint -- isn't @b\nFROM t WHERE x = $1::timestamptz AND y = $3"
+	if query != expected {
+		t.Errorf("expected query %q, got %q", expected, query)
+	}
+	if len(args) != 3 || args[0] != moment.UTC() || args[1] != 1 || args[2] != nil {
+		t.Errorf("expected arguments [%v 1 <nil>], got %v", moment.UTC(), args)
+	}
+}
+
+func TestMySQLDDL(t *testing.T) {
+	schema, err := os.ReadFile(filepath.Join("sql", "schema.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ddl, err := MySQL.DDL(string(schema), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, filepath.Join("testdata", "golden", "mysql", "schema.sql"), ddl)
+}
+
+func TestMySQLViews(t *testing.T) {
+	ctx := WithSystemMoment(context.Background(), time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC))
+	for name, test := range map[string]struct {
+		dialect MySQLDialect
+		view    string
+		with    bool
+	}{
+		"cte":            {MySQL, "`salaries$` s", true},
+		"derived_tables": {MySQLDialect{}, "(SELECT * FROM salaries WHERE " + derivedPredicate + ") s", false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			repo := &TemporalDB{dialect: test.dialect, temporalTables: goldenTables}
+			// $ words in literals and comments are not views
+			query := "SELECT s.salary, 'salaries$' -- titles$\nFROM " + repo.View("salaries", "s")
+			if expected := "SELECT s.salary, 'salaries$' -- titles$\nFROM " + test.view; query != expected {
+				t.Errorf("expected %q, got %q", expected, query)
+			}
+			prepared := repo.prepareQuery(ctx, QueryFragment{query, nil})
+			if strings.HasPrefix(prepared.Query, "WITH") != test.with || !strings.HasSuffix(prepared.Query, query) {
+				t.Errorf("expected the query to be kept as rendered, got %q", prepared.Query)
+			}
+		})
+	}
+}
+
+// checkGolden compares actual to the golden file at path, or rewrites the file with -update
+func checkGolden(t *testing.T, path, actual string) {
+	t.Helper()
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(actual), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%s, run go test -run %s -update to create it", err, t.Name())
+	}
+	if actual != string(expected) {
+		t.Errorf("%s is out of date, run go test -run %s -update and review the diff:\n%s", path, t.Name(), actual)
+	}
+}
+
+// goldenSQL appends the bound arguments to the query as comments
+func goldenSQL(query string, args []any) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(query))
+	b.WriteString("\n")
+	for i, arg := range args {
+		if named, ok := arg.(sql.NamedArg); ok {
+			fmt.Fprintf(&b, "-- @%s = %#v\n", named.Name, named.Value)
+		} else {
+			fmt.Fprintf(&b, "-- %d = %#v\n", i+1, arg)
+		}
+	}
+	return b.String()
+}
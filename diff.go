@@ -0,0 +1,134 @@
+package bitemporal
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+type DifferenceKind string
+
+const (
+	Inserted DifferenceKind = "inserted"
+	Removed  DifferenceKind = "removed"
+	Modified DifferenceKind = "modified"
+)
+
+// Difference is a valid period of an entity whose values differ between two transaction moments
+type Difference struct {
+	Key        map[string]any `json:"key"`
+	Kind       DifferenceKind `json:"kind"`
+	ValidOpen  time.Time      `json:"valid_open"`
+	ValidClose time.Time      `json:"valid_close"`
+	Old        map[string]any `json:"old"`
+	New        map[string]any `json:"new"`
+}
+
+// Diff compares the table as it was known at txnA with how it was known at txnB, across all of valid time.
+// Differences are ordered by key and then valid time.
+func (repo *TemporalDB) Diff(ctx context.Context, table string, txnA, txnB time.Time) ([]Difference, error) {
+	t, ok := repo.table(table)
+	if !ok {
+		return nil, fmt.Errorf("table %q is not registered", table)
+	}
+	if len(t.Keys) == 0 {
+		return nil, fmt.Errorf("table %q has no key columns", table)
+	}
+
+	query := fmt.Sprintf("SELECT %s, valid_open, valid_close, txn_open, txn_close FROM %s ORDER BY %s, valid_open",
+		strings.Join(t.Columns, ", "), repo.View(t.Name, ""), strings.Join(t.Keys, ", "))
+
+	// every version known at the moment, whatever its valid time
+	ctx = WithValidTime(ctx, time.Time{})
+	a, err := repo.queryVersions(WithSystemMoment(ctx, txnA), t, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	b, err := repo.queryVersions(WithSystemMoment(ctx, txnB), t, query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	groupsA, groupsB := groupByKey(t, a), groupByKey(t, b)
+	keys := make(map[string]map[string]any)
+	for _, groups := range []map[string][]version{groupsA, groupsB} {
+		for k, versions := range groups {
+			keys[k] = keyOf(t, versions[0])
+		}
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	slices.SortFunc(sorted, func(x, y string) int {
+		for _, column := range t.Keys {
+			if c := compareValues(keys[x][column], keys[y][column]); c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+
+	var differences []Difference
+	for _, k := range sorted {
+		for _, change := range comparePeriods(groupsA[k], groupsB[k]) {
+			kind := Modified
+			if change.Old == nil {
+				kind = Inserted
+			} else if change.New == nil {
+				kind = Removed
+			}
+			differences = append(differences, Difference{
+				Key:        keys[k],
+				Kind:       kind,
+				ValidOpen:  change.ValidOpen,
+				ValidClose: change.ValidClose,
+				Old:        change.Old,
+				New:        change.New,
+			})
+		}
+	}
+	return differences, nil
+}
+
+func keyOf(table Table, v version) map[string]any {
+	key := make(map[string]any, len(table.Keys))
+	for _, column := range table.Keys {
+		key[column] = v.Values[column]
+	}
+	return key
+}
+
+func groupByKey(table Table, versions []version) map[string][]version {
+	groups := make(map[string][]version)
+	for _, v := range versions {
+		k := fmt.Sprint(keyOf(table, v))
+		groups[k] = append(groups[k], v)
+	}
+	return groups
+}
+
+func compareValues(a, b any) int {
+	switch a := a.(type) {
+	case int64:
+		if b, ok := b.(int64); ok {
+			return cmp.Compare(a, b)
+		}
+	case float64:
+		if b, ok := b.(float64); ok {
+			return cmp.Compare(a, b)
+		}
+	case string:
+		if b, ok := b.(string); ok {
+			return cmp.Compare(a, b)
+		}
+	case time.Time:
+		if b, ok := b.(time.Time); ok {
+			return a.Compare(b)
+		}
+	}
+	return cmp.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
@@ -0,0 +1,72 @@
+package bitemporal_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pborges/bitemporal"
+	"github.com/pborges/bitemporal/model"
+)
+
+func TestDiff(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	temporalDB, err := bitemporal.NewTemporalDB(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := model.NewSalaryRepository(temporalDB)
+
+	ctx := bitemporal.WithTxnMoment(context.Background(), bitemporal.AsTime("2025-09-01 12:00:00"))
+	if _, err = repo.Save(ctx, model.Salary{EmpNo: 10009, Salary: 42}, bitemporal.AsTime("1995-01-01"), bitemporal.AsTime("2000-01-01")); err != nil {
+		t.Fatal(err)
+	}
+	ctx = bitemporal.WithTxnMoment(context.Background(), bitemporal.AsTime("2025-09-03 12:00:00"))
+	if _, err = repo.Terminate(ctx, bitemporal.AsTime("2001-01-01"), 10009); err != nil {
+		t.Fatal(err)
+	}
+
+	differences, err := temporalDB.Diff(context.Background(), "salaries", bitemporal.AsTime("2025-08-24"), bitemporal.AsTime("2025-09-02"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(differences) != 6 {
+		t.Errorf("Expected the 6 salaries overlapping the update window to be modified, got %d differences", len(differences))
+	}
+	for _, d := range differences {
+		if d.Kind != bitemporal.Modified || d.New["salary"] != int64(42) {
+			t.Errorf("Expected salary to be modified to 42, got %+v", d)
+		}
+		if d.Key["emp_no"] != int64(10009) {
+			t.Errorf("Expected difference for emp_no 10009, got %v", d.Key)
+		}
+	}
+	if len(differences) > 0 {
+		if first := differences[0]; !first.ValidOpen.Equal(bitemporal.AsTime("1995-01-01")) || first.Old["salary"] != int64(78335) {
+			t.Errorf("Expected the first difference to start at the update window, got %+v", first)
+		}
+	}
+
+	differences, err = temporalDB.Diff(context.Background(), "salaries", bitemporal.AsTime("2025-09-02"), bitemporal.AsTime("2025-09-04"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(differences) != 3 {
+		t.Errorf("Expected the 3 salaries after the termination to be removed, got %d differences", len(differences))
+	}
+	for _, d := range differences {
+		if d.Kind != bitemporal.Removed || d.New != nil {
+			t.Errorf("Expected salary to be removed, got %+v", d)
+		}
+	}
+
+	// the same moment has no differences
+	differences, err = temporalDB.Diff(context.Background(), "salaries", bitemporal.AsTime("2025-09-04"), bitemporal.AsTime("2025-09-04"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(differences) != 0 {
+		t.Errorf("Expected no differences comparing a moment with itself, got %d", len(differences))
+	}
+}
@@ -0,0 +1,116 @@
+package bitemporal
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// StorageFormat is the canonical encoding of the period columns: UTC with a fixed microsecond precision. Every
+// stored moment has the same width, so SQLite orders and compares them correctly as plain text.
+const StorageFormat = "2006-01-02T15:04:05.000000Z"
+
+// storageGlob matches a value in StorageFormat
+const storageGlob = "[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9]T[0-9][0-9]:[0-9][0-9]:[0-9][0-9].[0-9][0-9][0-9][0-9][0-9][0-9]Z"
+
+// FormatTime encodes a moment as it is stored, see StorageFormat
+func FormatTime(t time.Time) string {
+	return t.UTC().Format(StorageFormat)
+}
+
+// storageValue encodes a time in StorageFormat, so every time argument compares as text with the stored periods
+// whatever column or parameter name it is bound to. Other arguments are left to the driver.
+func storageValue(v any) any {
+	if t, ok := v.(time.Time); ok {
+		return FormatTime(t)
+	}
+	return v
+}
+
+// MigratePeriods rewrites the period columns of every record of the registered tables that is not stored in
+// StorageFormat, e.g. written by an earlier version or loaded from a script, and returns the number of records
+// rewritten. Queries compare the stored text, so run it once on a SQLite database written before the canonical
+// encoding, e.g. with cmd/migrate. It writes to the database, NewTemporalDB never runs it.
+func (repo *TemporalDB) MigratePeriods(ctx context.Context) (int64, error) {
+	if err := repo.requireSQLite("migrating periods"); err != nil {
+		return 0, err
+	}
+
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var migrated int64
+	for _, table := range repo.temporalTables {
+		n, err := migrateTable(ctx, tx, table.Name)
+		if err != nil {
+			return 0, fmt.Errorf("migrating %q: %w", table.Name, err)
+		}
+		migrated += n
+	}
+	return migrated, tx.Commit()
+}
+
+func migrateTable(ctx context.Context, tx *sql.Tx, table string) (int64, error) {
+	conditions := make([]string, len(temporalColumns))
+	assignments := make([]string, len(temporalColumns))
+	for i, column := range temporalColumns {
+		conditions[i] = fmt.Sprintf("%s NOT GLOB '%s'", column, storageGlob)
+		assignments[i] = column + " = ?"
+	}
+
+	// the records are read before any is rewritten, the connection cannot update a table it is scanning
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT rowid, %s FROM %s WHERE %s",
+		strings.Join(temporalColumns, ", "), table, strings.Join(conditions, " OR ")))
+	if err != nil {
+		return 0, err
+	}
+	var records [][]any
+	for rows.Next() {
+		record := make([]any, len(temporalColumns)+1)
+		ptrs := make([]any, len(record))
+		for i := range record {
+			ptrs[i] = &record[i]
+		}
+		if err = rows.Scan(ptrs...); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		records = append(records, record)
+	}
+	if err = rows.Err(); err != nil {
+		rows.Close()
+		return 0, err
+	}
+	if err = rows.Close(); err != nil {
+		return 0, err
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf("UPDATE %s SET %s WHERE rowid = ?", table, strings.Join(assignments, ", ")))
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	for _, record := range records {
+		values := make([]any, 0, len(record))
+		for _, v := range record[1:] {
+			t, err := asMoment(v)
+			if err != nil {
+				return 0, fmt.Errorf("row %v: %w", record[0], err)
+			}
+			values = append(values, FormatTime(t))
+		}
+		if _, err = stmt.ExecContext(ctx, append(values, record[0])...); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(records)), nil
+}
@@ -0,0 +1,152 @@
+package bitemporal_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/pborges/bitemporal"
+)
+
+func TestMigratePeriods(t *testing.T) {
+	db, err := sql.Open(bitemporal.SQLiteDriver, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if _, err = db.Exec(schema); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.Exec(tempDataQuery); err != nil {
+		t.Fatal(err)
+	}
+
+	temporalDB, err := bitemporal.NewTemporalDB(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// opening leaves the mixed formats of the test data alone, migrating rewrites them
+	var legacy string
+	if err = db.QueryRow(`SELECT CAST(valid_open AS TEXT) FROM salaries WHERE emp_no = 10009 ORDER BY valid_open LIMIT 1`).Scan(&legacy); err != nil {
+		t.Fatal(err)
+	}
+	if legacy != "1985-02-18" {
+		t.Errorf("Expected NewTemporalDB to leave 1985-02-18 as stored, got %s", legacy)
+	}
+	migrated, err := temporalDB.MigratePeriods(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migrated == 0 {
+		t.Error("Expected the test data to be migrated")
+	}
+
+	var validOpen, validClose, txnOpen, txnClose string
+	err = db.QueryRow(`SELECT CAST(valid_open AS TEXT), CAST(valid_close AS TEXT), CAST(txn_open AS TEXT), CAST(txn_close AS TEXT)
+		FROM salaries WHERE emp_no = 10009 ORDER BY valid_open LIMIT 1`).Scan(&validOpen, &validClose, &txnOpen, &txnClose)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"1985-02-18T00:00:00.000000Z", "1986-02-18T00:00:00.000000Z", "2025-08-23T15:55:49.371425Z", "9999-12-31T23:59:59.000000Z"}
+	for i, actual := range []string{validOpen, validClose, txnOpen, txnClose} {
+		if actual != expected[i] {
+			t.Errorf("Expected %s to be stored, got %s", expected[i], actual)
+		}
+	}
+
+	var unmigrated int
+	err = db.QueryRow(`SELECT COUNT(*) FROM salaries WHERE LENGTH(valid_open) != 27 OR LENGTH(valid_close) != 27
+		OR LENGTH(txn_open) != 27 OR LENGTH(txn_close) != 27`).Scan(&unmigrated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unmigrated != 0 {
+		t.Errorf("Expected every record to be migrated, %d were not", unmigrated)
+	}
+
+	migrated, err = temporalDB.MigratePeriods(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migrated != 0 {
+		t.Errorf("Expected a migrated database to be left alone, %d records were rewritten", migrated)
+	}
+
+	// writes keep the encoding and its precision
+	txnMoment := bitemporal.AsTime("2025-09-01 12:00:00.123456")
+	_, err = temporalDB.ApplyUpdateWindow(context.Background(), bitemporal.UpdateWindow{
+		Table:     "salaries",
+		Select:    []string{"emp_no", "salary"},
+		FilterBy:  []string{"emp_no"},
+		ValidFrom: bitemporal.AsTime("1995-01-01"),
+		ValidTo:   bitemporal.EndOfTime,
+		TxnMoment: txnMoment,
+		Values:    map[string]any{"emp_no": 10009, "salary": 42},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var closed, opened int
+	err = db.QueryRow(`SELECT SUM(txn_close = @moment), SUM(txn_open = @moment) FROM salaries`,
+		sql.Named("moment", bitemporal.FormatTime(txnMoment))).Scan(&closed, &opened)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the 9 records from 1994-02-16 are replaced by the part before the window and one update segment each
+	if closed != 9 || opened != 10 {
+		t.Errorf("Expected 9 records closed and 10 opened at %s, got %d and %d", bitemporal.FormatTime(txnMoment), closed, opened)
+	}
+}
+
+func TestTimeArguments(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	temporalDB, err := bitemporal.NewTemporalDB(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	moment := bitemporal.AsTime("2000-02-15")
+
+	// a time argument compares like its encoding whatever it is named, 2000-02-15 itself included
+	count := func(x any) int {
+		var n int
+		if err := temporalDB.QueryRow(ctx, "SELECT COUNT(*) FROM salaries WHERE emp_no = 10009 AND valid_open <= @x",
+			map[string]any{"x": x}).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	if byTime, byText := count(moment), count(bitemporal.FormatTime(moment)); byTime != byText || byTime != 16 {
+		t.Errorf("Expected 16 salaries opened by %s for a time and its encoding, got %d and %d", moment.Format(time.DateOnly), byTime, byText)
+	}
+
+	_, err = db.Exec(`INSERT INTO titles (emp_no, title, valid_open, valid_close, txn_open, txn_close)
+VALUES (10009, 'Engineer', '1985-02-18', '1995-01-01', '2025-08-23 08:55:49', '9999-12-31 23:59:59'),
+       (10009, 'Senior Engineer', '1995-01-01', '9999-12-31 23:59:59', '2025-08-23 08:55:49', '9999-12-31 23:59:59')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = temporalDB.MigratePeriods(ctx); err != nil {
+		t.Fatal(err)
+	}
+	join := func(x any) int {
+		periods, err := temporalDB.TemporalJoin(ctx, bitemporal.Join{
+			Left:   "salaries",
+			Right:  "titles",
+			Where:  "l.emp_no = 10009 AND l.valid_open <= @x",
+			ArgMap: map[string]any{"x": x},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(periods)
+	}
+	if byTime, byText := join(moment), join(bitemporal.FormatTime(moment)); byTime != byText || byTime != 17 {
+		t.Errorf("Expected 17 joined periods for a time and its encoding, got %d and %d", byTime, byText)
+	}
+}
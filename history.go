@@ -0,0 +1,190 @@
+package bitemporal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// BeliefChange is a change in what was believed about part of the valid time of an entity. Old is nil when nothing
+// was believed about the period before the change and New is nil when the belief was retracted.
+type BeliefChange struct {
+	TxnMoment  time.Time      `json:"txn_moment"`
+	ValidOpen  time.Time      `json:"valid_open"`
+	ValidClose time.Time      `json:"valid_close"`
+	Old        map[string]any `json:"old"`
+	New        map[string]any `json:"new"`
+}
+
+// version is a single record of a table, Values holds every column in Table.Columns
+type version struct {
+	Values map[string]any
+	Entity
+}
+
+// periodChange is a valid period whose values differ between two sets of versions
+type periodChange struct {
+	ValidOpen  time.Time
+	ValidClose time.Time
+	Old        map[string]any
+	New        map[string]any
+}
+
+// History returns every change in belief about the entity identified by key, ordered by transaction moment and
+// then valid time. It is computed from the transaction periods of every record ever written for the key.
+func (repo *TemporalDB) History(ctx context.Context, table string, key map[string]any) ([]BeliefChange, error) {
+	t, ok := repo.table(table)
+	if !ok {
+		return nil, fmt.Errorf("table %q is not registered", table)
+	}
+	filterBy, err := keyColumns(t, key)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := make([]string, len(filterBy))
+	for i, column := range filterBy {
+		filters[i] = column + " = @" + column
+	}
+	versions, err := repo.queryVersions(ctx, t, fmt.Sprintf("SELECT %s, valid_open, valid_close, txn_open, txn_close FROM %s WHERE %s ORDER BY txn_open, valid_open",
+		strings.Join(t.Columns, ", "), t.Name, strings.Join(filters, " AND ")), key)
+	if err != nil {
+		return nil, err
+	}
+
+	var moments []time.Time
+	for _, v := range versions {
+		moments = append(moments, v.TxnOpen)
+		if v.TxnClose.Before(EndOfTime) {
+			moments = append(moments, v.TxnClose)
+		}
+	}
+	slices.SortFunc(moments, time.Time.Compare)
+	moments = slices.CompactFunc(moments, time.Time.Equal)
+
+	var changes []BeliefChange
+	for _, moment := range moments {
+		var before, after []version
+		for _, v := range versions {
+			// believed up until the moment
+			if v.TxnOpen.Before(moment) && !v.TxnClose.Before(moment) {
+				before = append(before, v)
+			}
+			// believed from the moment
+			if !v.TxnOpen.After(moment) && v.TxnClose.After(moment) {
+				after = append(after, v)
+			}
+		}
+
+		for _, change := range comparePeriods(before, after) {
+			changes = append(changes, BeliefChange{
+				TxnMoment:  moment,
+				ValidOpen:  change.ValidOpen,
+				ValidClose: change.ValidClose,
+				Old:        change.Old,
+				New:        change.New,
+			})
+		}
+	}
+	return changes, nil
+}
+
+func (repo *TemporalDB) queryVersions(ctx context.Context, table Table, query string, args map[string]any) ([]version, error) {
+	rows, err := repo.Query(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []version
+	for rows.Next() {
+		values := make([]any, len(table.Columns))
+		v := version{Values: make(map[string]any, len(table.Columns))}
+		targets := make([]any, 0, len(table.Columns)+4)
+		for i := range values {
+			targets = append(targets, &values[i])
+		}
+		targets = append(targets, &v.ValidOpen, &v.ValidClose, &v.TxnOpen, &v.TxnClose)
+		if err = rows.Scan(targets...); err != nil {
+			return nil, err
+		}
+		for i, column := range table.Columns {
+			v.Values[column] = values[i]
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+// comparePeriods splits valid time at every boundary of old and new and returns the periods whose values differ,
+// adjacent periods with the same change are merged. Where versions overlap the most recently recorded one wins.
+func comparePeriods(old, new []version) []periodChange {
+	var boundaries []time.Time
+	for _, v := range slices.Concat(old, new) {
+		boundaries = append(boundaries, v.ValidOpen, v.ValidClose)
+	}
+	slices.SortFunc(boundaries, time.Time.Compare)
+	boundaries = slices.CompactFunc(boundaries, time.Time.Equal)
+
+	var changes []periodChange
+	for i := 0; i+1 < len(boundaries); i++ {
+		from, to := boundaries[i], boundaries[i+1]
+		o, n := covering(old, from, to), covering(new, from, to)
+		if valuesEqual(o, n) {
+			continue
+		}
+
+		if last := len(changes) - 1; last >= 0 && changes[last].ValidClose.Equal(from) &&
+			valuesEqual(changes[last].Old, o) && valuesEqual(changes[last].New, n) {
+			changes[last].ValidClose = to
+			continue
+		}
+		changes = append(changes, periodChange{ValidOpen: from, ValidClose: to, Old: o, New: n})
+	}
+	return changes
+}
+
+// covering returns the values of the most recently recorded version valid over all of [from, to)
+func covering(versions []version, from, to time.Time) map[string]any {
+	var found *version
+	for i, v := range versions {
+		if v.ValidOpen.After(from) || v.ValidClose.Before(to) {
+			continue
+		}
+		if found == nil || v.TxnOpen.After(found.TxnOpen) {
+			found = &versions[i]
+		}
+	}
+	if found == nil {
+		return nil
+	}
+	return found.Values
+}
+
+func valuesEqual(a, b map[string]any) bool {
+	if (a == nil) != (b == nil) || len(a) != len(b) {
+		return false
+	}
+	for k, av := range a {
+		bv, ok := b[k]
+		if !ok || !valueEqual(av, bv) {
+			return false
+		}
+	}
+	return true
+}
+
+func valueEqual(a, b any) bool {
+	switch a := a.(type) {
+	case time.Time:
+		b, ok := b.(time.Time)
+		return ok && a.Equal(b)
+	case []byte:
+		b, ok := b.([]byte)
+		return ok && bytes.Equal(a, b)
+	}
+	return a == b
+}
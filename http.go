@@ -0,0 +1,73 @@
+package bitemporal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// The headers and query parameters HTTPMiddleware builds the TemporalContext from
+const (
+	ValidTimeHeader = "X-Valid-Time"
+	KnownAtHeader   = "X-Known-At"
+	ValidTimeParam  = "valid_at"
+	KnownAtParam    = "known_at"
+)
+
+// HTTPMiddleware initializes the TemporalContext of every request to now, then applies the valid time and the
+// system moment given by the X-Valid-Time and X-Known-At headers, or the valid_at and known_at query parameters
+// which take precedence. Malformed times are rejected with a 400, see WriteError.
+func HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := InitializeContext(r.Context())
+
+		validTime, err := requestTime(r, ValidTimeHeader, ValidTimeParam)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		if !validTime.IsZero() {
+			ctx = WithValidTime(ctx, validTime)
+		}
+
+		knownAt, err := requestTime(r, KnownAtHeader, KnownAtParam)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		if !knownAt.IsZero() {
+			ctx = WithSystemMoment(ctx, knownAt)
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// HasValidTime reports whether the request gives a valid time, when it does not HTTPMiddleware defaults it to now
+func HasValidTime(r *http.Request) bool {
+	return r.Header.Get(ValidTimeHeader) != "" || r.URL.Query().Get(ValidTimeParam) != ""
+}
+
+// requestTime returns the time in the query parameter, or else the header, or the zero time when neither is set
+func requestTime(r *http.Request, header, param string) (time.Time, error) {
+	name, value := param, r.URL.Query().Get(param)
+	if value == "" {
+		name, value = header, r.Header.Get(header)
+	}
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := ParseTime(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return t, nil
+}
+
+// WriteError responds with the error as JSON, {"error": "..."}
+func WriteError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,90 @@
+package bitemporal_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pborges/bitemporal"
+)
+
+func TestHTTPMiddleware(t *testing.T) {
+	var validTime, knownAt time.Time
+	handler := bitemporal.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		validTime = bitemporal.GetValidMoment(r.Context())
+		knownAt = bitemporal.GetSystemMoment(r.Context())
+	}))
+
+	var body string
+	serve := func(target string, headers map[string]string) int {
+		validTime, knownAt = time.Time{}, time.Time{}
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		body = w.Body.String()
+		return w.Code
+	}
+
+	// without times both default to now
+	before := time.Now()
+	if code := serve("/", nil); code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	if validTime.Before(before) || knownAt.Before(before) {
+		t.Errorf("Expected both times to default to now, got %s and %s", validTime, knownAt)
+	}
+
+	code := serve("/", map[string]string{
+		bitemporal.ValidTimeHeader: "1993-02-17",
+		bitemporal.KnownAtHeader:   "2025-08-23T08:55:49.371425-07:00",
+	})
+	if code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	if !validTime.Equal(bitemporal.AsTime("1993-02-17")) {
+		t.Errorf("Expected the valid time from the header, got %s", validTime)
+	}
+	if !knownAt.Equal(bitemporal.AsTime("2025-08-23 15:55:49.371425")) {
+		t.Errorf("Expected the known at from the header, got %s", knownAt)
+	}
+
+	// query parameters take precedence over headers
+	code = serve("/?valid_at=2000-01-01+12:00:00&known_at=Mon,+01+Sep+2025+12:00:00+GMT", map[string]string{
+		bitemporal.ValidTimeHeader: "1993-02-17",
+	})
+	if code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	if !validTime.Equal(bitemporal.AsTime("2000-01-01 12:00:00")) {
+		t.Errorf("Expected the valid time from the query, got %s", validTime)
+	}
+	if !knownAt.Equal(testTxnMoment) {
+		t.Errorf("Expected the known at from the query, got %s", knownAt)
+	}
+
+	// -infinity is before everything rather than unset
+	if code = serve("/?known_at=-infinity", nil); code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	if !knownAt.Equal(bitemporal.StartOfTime) {
+		t.Errorf("Expected -infinity to be the start of time, got %s", knownAt)
+	}
+
+	for _, target := range []string{"/?valid_at=yesterday", "/?known_at=2025-13-01"} {
+		if code = serve(target, nil); code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", target, code)
+		}
+		var response map[string]string
+		if err := json.Unmarshal([]byte(body), &response); err != nil || response["error"] == "" {
+			t.Errorf("Expected a JSON error for %s, got %q", target, body)
+		}
+	}
+	if code = serve("/", map[string]string{bitemporal.KnownAtHeader: "soon"}); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a malformed header, got %d", code)
+	}
+}
@@ -0,0 +1,133 @@
+package bitemporal
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Join is a sequenced join of two registered tables: records are paired when their keys match and they were valid,
+// and believed, at the same time
+type Join struct {
+	Left  string
+	Right string
+	// On are the columns matched between both tables, it defaults to the key columns they have in common
+	On []string
+	// Where further restricts the pairs, the records are aliased l and r
+	Where  string
+	ArgMap map[string]any
+}
+
+// JoinedPeriod is a pair of joined records over the intersection of their periods
+type JoinedPeriod struct {
+	Left  map[string]any `json:"left"`
+	Right map[string]any `json:"right"`
+	Entity
+}
+
+// JoinQuery generates the interval-intersection query of the join over the temporal views of both tables, it selects
+// the columns of the left table, those of the right table and then the intersected temporal columns
+func (repo *TemporalDB) JoinQuery(join Join) (QueryFragment, error) {
+	left, ok := repo.table(join.Left)
+	if !ok {
+		return QueryFragment{}, fmt.Errorf("table %q is not registered", join.Left)
+	}
+	right, ok := repo.table(join.Right)
+	if !ok {
+		return QueryFragment{}, fmt.Errorf("table %q is not registered", join.Right)
+	}
+
+	on := join.On
+	if len(on) == 0 {
+		for _, key := range left.Keys {
+			if slices.Contains(right.Keys, key) {
+				on = append(on, key)
+			}
+		}
+		if len(on) == 0 {
+			return QueryFragment{}, fmt.Errorf("tables %q and %q have no key columns in common", left.Name, right.Name)
+		}
+	}
+	conditions := make([]string, 0, len(on)+3)
+	for _, column := range on {
+		if !slices.Contains(left.Columns, column) || !slices.Contains(right.Columns, column) {
+			return QueryFragment{}, fmt.Errorf("column %q is not in both %q and %q", column, left.Name, right.Name)
+		}
+		conditions = append(conditions, fmt.Sprintf("l.%s = r.%s", column, column))
+	}
+	conditions = append(conditions,
+		"l.valid_open < r.valid_close AND r.valid_open < l.valid_close",
+		"l.txn_open < r.txn_close AND r.txn_open < l.txn_close")
+	if join.Where != "" {
+		conditions = append(conditions, "("+join.Where+")")
+	}
+
+	order := make([]string, len(left.Keys))
+	for i, key := range left.Keys {
+		order[i] = "l." + key
+	}
+
+	query := fmt.Sprintf(`SELECT l.%s, r.%s,
+    %s valid_open,
+    %s valid_close,
+    %s txn_open,
+    %s txn_close
+FROM %s
+JOIN %s ON %s
+ORDER BY %s, valid_open, txn_open`,
+		strings.Join(left.Columns, ", l."), strings.Join(right.Columns, ", r."),
+		repo.greatest("l.valid_open", "r.valid_open"), repo.least("l.valid_close", "r.valid_close"),
+		repo.greatest("l.txn_open", "r.txn_open"), repo.least("l.txn_close", "r.txn_close"),
+		repo.View(left.Name, "l"), repo.View(right.Name, "r"), strings.Join(conditions, "\n  AND "), strings.Join(order, ", "))
+	return QueryFragment{Query: query, ArgMap: join.ArgMap}, nil
+}
+
+// TemporalJoin returns one JoinedPeriod per overlapping sub-period of the joined tables, as visible in the
+// TemporalContext
+func (repo *TemporalDB) TemporalJoin(ctx context.Context, join Join) ([]JoinedPeriod, error) {
+	fragment, err := repo.JoinQuery(join)
+	if err != nil {
+		return nil, err
+	}
+	left, _ := repo.table(join.Left)
+	right, _ := repo.table(join.Right)
+
+	rows, err := repo.Query(ctx, fragment.Query, fragment.ArgMap)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	width := len(left.Columns) + len(right.Columns)
+	var periods []JoinedPeriod
+	for rows.Next() {
+		record := make([]any, width+4)
+		ptrs := make([]any, len(record))
+		for i := range record {
+			ptrs[i] = &record[i]
+		}
+		if err = rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+
+		period := JoinedPeriod{
+			Left:  make(map[string]any, len(left.Columns)),
+			Right: make(map[string]any, len(right.Columns)),
+		}
+		for i, column := range left.Columns {
+			period.Left[column] = record[i]
+		}
+		for i, column := range right.Columns {
+			period.Right[column] = record[len(left.Columns)+i]
+		}
+		for i, moment := range []*time.Time{&period.ValidOpen, &period.ValidClose, &period.TxnOpen, &period.TxnClose} {
+			if *moment, err = asMoment(record[width+i]); err != nil {
+				return nil, err
+			}
+		}
+		periods = append(periods, period)
+	}
+	return periods, rows.Err()
+}
@@ -0,0 +1,74 @@
+package bitemporal_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pborges/bitemporal"
+)
+
+func TestTemporalJoin(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+	_, err := db.Exec(`INSERT INTO titles (emp_no, title, valid_open, valid_close, txn_open, txn_close)
+VALUES (10009, 'Engineer', '1985-02-18', '1995-01-01', '2025-08-23 08:55:49', '9999-12-31 23:59:59'),
+       (10009, 'Senior Engineer', '1995-01-01', '9999-12-31 23:59:59', '2025-08-23 08:55:49', '9999-12-31 23:59:59')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	temporalDB, err := bitemporal.NewTemporalDB(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// salary history with the title held at the time
+	join := bitemporal.Join{Left: "salaries", Right: "titles"}
+	periods, err := temporalDB.TemporalJoin(context.Background(), join)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the salary of 1994-02-16 to 1995-02-16 is split by the promotion
+	if len(periods) != 19 {
+		t.Fatalf("Expected 19 periods, 18 salaries with one split in two, got %d", len(periods))
+	}
+	for i := 1; i < len(periods); i++ {
+		if !periods[i].ValidOpen.Equal(periods[i-1].ValidClose) {
+			t.Errorf("Expected period %d to start when period %d ends, got %s and %s", i, i-1, periods[i-1].Entity, periods[i].Entity)
+		}
+	}
+	for _, period := range periods {
+		if !period.ValidOpen.Equal(bitemporal.AsTime("1994-02-16")) && !period.ValidOpen.Equal(bitemporal.AsTime("1995-01-01")) {
+			continue
+		}
+		if period.Left["salary"] != int64(78335) {
+			t.Errorf("Expected the split salary to be 78335, got %v", period.Left["salary"])
+		}
+		if period.ValidOpen.Year() == 1994 && (period.Right["title"] != "Engineer" || !period.ValidClose.Equal(bitemporal.AsTime("1995-01-01"))) {
+			t.Errorf("Expected to be an Engineer until 1995-01-01, got %v", period)
+		}
+		if period.ValidOpen.Year() == 1995 && (period.Right["title"] != "Senior Engineer" || !period.ValidClose.Equal(bitemporal.AsTime("1995-02-16"))) {
+			t.Errorf("Expected to be a Senior Engineer until the next raise, got %v", period)
+		}
+	}
+
+	// the join respects the TemporalContext
+	ctx := bitemporal.WithValidTime(context.Background(), bitemporal.AsTime("1990-06-01"))
+	periods, err = temporalDB.TemporalJoin(ctx, join)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(periods) != 1 || periods[0].Left["salary"] != int64(70889) || periods[0].Right["title"] != "Engineer" {
+		t.Errorf("Expected a single Engineer earning 70889, got %v", periods)
+	}
+
+	join.Where = "r.title = @title"
+	join.ArgMap = map[string]any{"title": "Senior Engineer"}
+	periods, err = temporalDB.TemporalJoin(context.Background(), join)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(periods) != 9 || !periods[0].ValidOpen.Equal(bitemporal.AsTime("1995-01-01")) {
+		t.Errorf("Expected the 9 salaries since the promotion, got %d", len(periods))
+	}
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pborges/bitemporal"
+)
+
+func init() {
+	bitemporal.DefaultRegistry.MustRegister(bitemporal.MustTableOf[DeptEmp]())
+}
+
+// DeptEmp assigns an employee to a department, the assignment is only valid while both exist
+type DeptEmp struct {
+	EmpNo             int64  `json:"emp_no" db:"emp_no,key,references=employees"`
+	DeptNo            string `json:"dept_no" db:"dept_no,key,references=departments"`
+	bitemporal.Entity `db:"dept_emp"`
+}
+
+func (d DeptEmp) String() string {
+	return fmt.Sprintf("DeptEmp{EmpNo: %d, DeptNo: %s}", d.EmpNo, d.DeptNo)
+}
+
+func NewDeptEmpRepository(repo *bitemporal.TemporalDB) *DeptEmpRepository {
+	return &DeptEmpRepository{
+		Repository: bitemporal.NewRepository[DeptEmp](repo),
+	}
+}
+
+type DeptEmpRepository struct {
+	*bitemporal.Repository[DeptEmp]
+}
+
+func (r DeptEmpRepository) ForEmployee(ctx context.Context, empNo int64) ([]DeptEmp, error) {
+	return r.List(ctx, empNo)
+}
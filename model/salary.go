@@ -8,19 +8,13 @@ import (
 )
 
 func init() {
-	bitemporal.Schema = append(bitemporal.Schema, bitemporal.Table{
-		Name: "salaries",
-		Columns: []string{
-			"emp_no",
-			"salary",
-		},
-	})
+	bitemporal.DefaultRegistry.MustRegister(bitemporal.MustTableOf[Salary]())
 }
 
 type Salary struct {
-	EmpNo  int64 `json:"emp_no"`
-	Salary int64 `json:"salary"`
-	bitemporal.Entity
+	EmpNo             int64 `json:"emp_no" db:"emp_no,key"`
+	Salary            int64 `json:"salary" db:"salary"`
+	bitemporal.Entity `db:"salaries"`
 }
 
 func (s Salary) String() string {
@@ -29,48 +23,18 @@ func (s Salary) String() string {
 
 func NewSalaryRepository(repo *bitemporal.TemporalDB) *SalaryRepository {
 	return &SalaryRepository{
-		repo: repo,
+		Repository: bitemporal.NewRepository[Salary](repo),
 	}
 }
 
 type SalaryRepository struct {
-	repo *bitemporal.TemporalDB
+	*bitemporal.Repository[Salary]
 }
 
 func (r SalaryRepository) ForEmployee(ctx context.Context, empNo int64) ([]Salary, error) {
-	rows, err := r.repo.Query(ctx, "SELECT emp_no, salary, valid_close, valid_open, txn_open, txn_close FROM salaries$ WHERE emp_no=@emp_no ORDER BY txn_open, valid_close", map[string]any{"emp_no": empNo})
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var salaries []Salary
-	for rows.Next() {
-		salary := Salary{}
-		err := rows.Scan(&salary.EmpNo, &salary.Salary, &salary.ValidClose, &salary.ValidOpen, &salary.TxnOpen, &salary.TxnClose)
-		if err != nil {
-			return nil, err
-		}
-		salaries = append(salaries, salary)
-	}
-	return salaries, nil
+	return r.List(ctx, empNo)
 }
 
 func (r SalaryRepository) AllRecords(ctx context.Context, empNo int64) ([]Salary, error) {
-	rows, err := r.repo.Query(ctx, "SELECT emp_no, salary, valid_open, valid_close, txn_open, txn_close FROM salaries WHERE emp_no=@emp_no ORDER BY txn_open, valid_open", map[string]any{"emp_no": empNo})
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var salaries []Salary
-	for rows.Next() {
-		var salary Salary
-		err = rows.Scan(&salary.EmpNo, &salary.Salary, &salary.ValidOpen, &salary.ValidClose, &salary.TxnOpen, &salary.TxnClose)
-		if err != nil {
-			return nil, err
-		}
-		salaries = append(salaries, salary)
-	}
-	return salaries, nil
+	return r.History(ctx, empNo)
 }
@@ -8,19 +8,13 @@ import (
 )
 
 func init() {
-	bitemporal.Schema = append(bitemporal.Schema, bitemporal.Table{
-		Name: "titles",
-		Columns: []string{
-			"emp_no",
-			"title",
-		},
-	})
+	bitemporal.DefaultRegistry.MustRegister(bitemporal.MustTableOf[Title]())
 }
 
 type Title struct {
-	EmpNo int64  `json:"emp_no"`
-	Title string `json:"title"`
-	bitemporal.Entity
+	EmpNo             int64  `json:"emp_no" db:"emp_no,key"`
+	Title             string `json:"title" db:"title"`
+	bitemporal.Entity `db:"titles"`
 }
 
 func (t Title) String() string {
@@ -29,48 +23,18 @@ func (t Title) String() string {
 
 func NewTitleRepository(repo *bitemporal.TemporalDB) *TitleRepository {
 	return &TitleRepository{
-		repo: repo,
+		Repository: bitemporal.NewRepository[Title](repo),
 	}
 }
 
 type TitleRepository struct {
-	repo *bitemporal.TemporalDB
+	*bitemporal.Repository[Title]
 }
 
 func (r TitleRepository) ForEmployee(ctx context.Context, empNo int64) ([]Title, error) {
-	rows, err := r.repo.Query(ctx, "SELECT emp_no, title, valid_close, valid_open, txn_open, txn_close FROM titles$ WHERE emp_no=@emp_no ORDER BY txn_open, valid_close", map[string]any{"emp_no": empNo})
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var titles []Title
-	for rows.Next() {
-		title := Title{}
-		err := rows.Scan(&title.EmpNo, &title.Title, &title.ValidClose, &title.ValidOpen, &title.TxnOpen, &title.TxnClose)
-		if err != nil {
-			return nil, err
-		}
-		titles = append(titles, title)
-	}
-	return titles, nil
+	return r.List(ctx, empNo)
 }
 
 func (r TitleRepository) AllRecords(ctx context.Context, empNo int64) ([]Title, error) {
-	rows, err := r.repo.Query(ctx, "SELECT emp_no, title, valid_open, valid_close, txn_open, txn_close FROM titles WHERE emp_no=@emp_no ORDER BY txn_open, valid_open", map[string]any{"emp_no": empNo})
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var titles []Title
-	for rows.Next() {
-		var title Title
-		err = rows.Scan(&title.EmpNo, &title.Title, &title.ValidOpen, &title.ValidClose, &title.TxnOpen, &title.TxnClose)
-		if err != nil {
-			return nil, err
-		}
-		titles = append(titles, title)
-	}
-	return titles, nil
+	return r.History(ctx, empNo)
 }
@@ -0,0 +1,121 @@
+package bitemporal
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MySQL is the Dialect of MySQL 8 and MariaDB 10.2 or later, see MySQLDialect
+var MySQL = MySQLDialect{CTE: true}
+
+// MySQLDialect binds @name parameters as ? placeholders and stores moments as DATETIME(6) in UTC. Queries, update
+// windows and TemporalJoin are portable, coalescing update windows need CTE. Batch update windows, Audit,
+// OverlapCheck, MigratePeriods and Aggregate sampled at intervals return ErrUnsupported.
+//
+// The Session statements only set up the connection NewTemporalDB runs them on, set the time zone of a pool in the
+// DSN instead, e.g. "?loc=UTC&time_zone=%27%2B00%3A00%27".
+type MySQLDialect struct {
+	// CTE names the temporal views with a WITH clause, without it TemporalDB.View renders every reference to a view
+	// as a derived table, so queries must not name the views themselves, see DetectMySQL
+	CTE bool
+}
+
+// DetectMySQL returns the MySQLDialect for the server behind db: common table expressions are used from MySQL 8 and
+// MariaDB 10.2
+func DetectMySQL(ctx context.Context, db *sql.DB) (MySQLDialect, error) {
+	var version string
+	if err := db.QueryRowContext(ctx, "SELECT VERSION()").Scan(&version); err != nil {
+		return MySQLDialect{}, err
+	}
+	major, minor, err := mysqlVersion(version)
+	if err != nil {
+		return MySQLDialect{}, err
+	}
+	if strings.Contains(strings.ToLower(version), "mariadb") {
+		return MySQLDialect{CTE: major > 10 || major == 10 && minor >= 2}, nil
+	}
+	return MySQLDialect{CTE: major >= 8}, nil
+}
+
+// mysqlVersion parses the major and minor version out of e.g. "8.0.36" or "10.11.6-MariaDB"
+func mysqlVersion(version string) (int, int, error) {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, fmt.Errorf("unexpected MySQL version %q", version)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("unexpected MySQL version %q", version)
+	}
+	minor, err := strconv.Atoi(strings.TrimRightFunc(parts[1], func(r rune) bool { return r < '0' || r > '9' }))
+	if err != nil {
+		return 0, 0, fmt.Errorf("unexpected MySQL version %q", version)
+	}
+	return major, minor, nil
+}
+
+func (MySQLDialect) Name() string {
+	return "mysql"
+}
+
+// Bind binds the argument of a parameter again for every use, MySQL has no numbered placeholders
+func (MySQLDialect) Bind(query string, args map[string]any) (string, []any) {
+	query, bound := bindPositional(query, args, true, func(string, int) string {
+		return "?"
+	})
+	for i, v := range bound {
+		if t, ok := v.(time.Time); ok {
+			bound[i] = t.UTC()
+		}
+	}
+	return query, bound
+}
+
+func (MySQLDialect) Timestamp(t time.Time) string {
+	return "TIMESTAMP '" + t.UTC().Format("2006-01-02 15:04:05.000000") + "'"
+}
+
+func (d MySQLDialect) Infinity() string {
+	return d.Timestamp(EndOfTime)
+}
+
+// ViewName quotes the name, not every MySQL compatible server accepts $ in identifiers
+func (MySQLDialect) ViewName(table string) string {
+	return "`" + table + "$`"
+}
+
+func (MySQLDialect) Session() []string {
+	return []string{"SET time_zone = '+00:00'"}
+}
+
+func (MySQLDialect) ColumnsQuery() string {
+	return "SELECT column_name FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = @table ORDER BY ordinal_position"
+}
+
+func (d MySQLDialect) derivedViews() bool {
+	return !d.CTE
+}
+
+var (
+	sqliteText        = regexp.MustCompile(`\bTEXT(\s+)`)
+	createIndexExists = regexp.MustCompile(`(?i)CREATE INDEX IF NOT EXISTS`)
+)
+
+// DDL translates the SQLite schema, e.g. sql/schema.sql, to MySQL. Text columns become VARCHAR(255) so they can be
+// indexed. The registry is unused, it may be nil, the signature matches PostgresDialect.DDL.
+func (d MySQLDialect) DDL(schema string, registry *Registry) (string, error) {
+	schema = sqliteIdentity.ReplaceAllString(schema, "BIGINT${1}NOT NULL AUTO_INCREMENT PRIMARY KEY")
+	schema = sqliteInteger.ReplaceAllString(schema, "BIGINT")
+	schema = sqliteDatetime.ReplaceAllString(schema, "DATETIME(6)")
+	schema = sqliteText.ReplaceAllString(schema, "VARCHAR(255)${1}")
+	schema = sqliteNow.ReplaceAllString(schema, "DEFAULT CURRENT_TIMESTAMP(6)")
+	schema = createIndexExists.ReplaceAllString(schema, "CREATE INDEX")
+	return replaceMoments(schema, func(t time.Time) string {
+		return "'" + t.UTC().Format("2006-01-02 15:04:05.000000") + "'"
+	})
+}
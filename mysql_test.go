@@ -0,0 +1,143 @@
+package bitemporal_test
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"testing"
+
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/server"
+	gmssql "github.com/dolthub/go-mysql-server/sql"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/pborges/bitemporal"
+	"github.com/pborges/bitemporal/model"
+)
+
+// createMySQLTestDB serves an in-process MySQL compatible database holding sql/schema.sql and the valid time data
+func createMySQLTestDB(t *testing.T, dialect bitemporal.MySQLDialect, interpolateParams bool) (*bitemporal.TemporalDB, *model.EmployeeRepository) {
+	provider := memory.NewDBProvider(memory.NewDatabase("bitemporal"))
+	srv, err := server.NewServer(server.Config{Protocol: "tcp", Address: "127.0.0.1:0"},
+		sqle.NewDefault(provider), gmssql.NewContext, memory.NewSessionBuilder(provider), nil)
+	if err != nil {
+		t.Fatalf("Failed to create MySQL server: %v", err)
+	}
+	go srv.Start()
+	t.Cleanup(func() { srv.Close() })
+
+	// the driver prepares statements on the server unless it interpolates the parameters itself
+	db, err := sql.Open("mysql", "root@tcp("+srv.Listener.Addr().String()+")/bitemporal"+
+		"?multiStatements=true&parseTime=true&loc=UTC&interpolateParams="+strconv.FormatBool(interpolateParams))
+	if err != nil {
+		t.Fatalf("Failed to open MySQL database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	schema, err := dialect.DDL(temporalSchema, nil)
+	if err != nil {
+		t.Fatalf("Failed to translate schema: %v", err)
+	}
+	if _, err = db.Exec(schema); err != nil {
+		t.Fatalf("Failed to execute schema: %v", err)
+	}
+	if _, err = db.Exec(validTimeData); err != nil {
+		t.Fatalf("Failed to execute test data: %v", err)
+	}
+
+	detected, err := bitemporal.DetectMySQL(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !detected.CTE {
+		t.Errorf("Expected common table expressions to be detected on the engine")
+	}
+
+	temporalDB, err := bitemporal.NewTemporalDB(db, bitemporal.WithDialect(dialect))
+	if err != nil {
+		t.Fatalf("Failed to create TemporalDB: %v", err)
+	}
+	return temporalDB, model.NewEmployeeRepository(temporalDB)
+}
+
+func TestMySQL(t *testing.T) {
+	for name, test := range map[string]struct {
+		dialect           bitemporal.MySQLDialect
+		interpolateParams bool
+	}{
+		"cte":              {bitemporal.MySQL, false},
+		"cte_interpolated": {bitemporal.MySQL, true},
+		"derived_tables":   {bitemporal.MySQLDialect{}, false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			temporalDB, repo := createMySQLTestDB(t, test.dialect, test.interpolateParams)
+
+			employee := queryEmployeeAtTime(t, repo, 12345, bitemporal.AsTime("2023-06-16"), bitemporal.AsTime("2023-07-05 23:59:59"))
+			if employee.LastName != "Johnson" {
+				t.Errorf("Expected last name 'Johnson' (after recorded marriage date), got '%s'", employee.LastName)
+			}
+			employee = queryEmployeeAtTime(t, repo, 12345, bitemporal.AsTime("2023-06-12"), bitemporal.AsTime("2023-06-20 23:59:59"))
+			if employee.LastName != "Smith" {
+				t.Errorf("Expected last name 'Smith' (before recorded marriage date), got '%s'", employee.LastName)
+			}
+
+			ctx := bitemporal.WithTxnMoment(context.Background(), bitemporal.AsTime("2024-01-02 09:00:00"))
+			saved, err := repo.Save(ctx, model.Employee{
+				EmpNo:     12345,
+				BirthDate: bitemporal.AsTime("1990-03-15"),
+				FirstName: "Jane",
+				LastName:  "O'Brien",
+				Gender:    "F",
+				HireDate:  bitemporal.AsTime("2020-01-15"),
+			}, bitemporal.AsTime("2023-06-10"), bitemporal.EndOfTime)
+			if err != nil {
+				t.Fatalf("Failed to save employee: %v", err)
+			}
+			for _, emp := range saved {
+				if !emp.TxnOpen.Equal(bitemporal.AsTime("2024-01-02 09:00:00")) {
+					t.Errorf("Expected saved rows to be recorded at the context transaction moment, got %s", emp.TxnOpen)
+				}
+			}
+
+			employee = queryEmployeeAtTime(t, repo, 12345, bitemporal.AsTime("2023-06-12"), bitemporal.AsTime("2024-01-03"))
+			if employee.LastName != "O'Brien" {
+				t.Errorf("Expected last name 'O'Brien' after save, got '%s'", employee.LastName)
+			}
+			employee = queryEmployeeAtTime(t, repo, 12345, bitemporal.AsTime("2023-06-12"), bitemporal.AsTime("2023-12-31"))
+			if employee.LastName != "Johnson" {
+				t.Errorf("Expected last name 'Johnson' before the save was recorded, got '%s'", employee.LastName)
+			}
+			employee = queryEmployeeAtTime(t, repo, 12345, bitemporal.AsTime("2023-06-09"), bitemporal.AsTime("2024-01-03"))
+			if employee.LastName != "Smith" {
+				t.Errorf("Expected last name 'Smith' before the window, got '%s'", employee.LastName)
+			}
+
+			ctx = bitemporal.WithSystemMoment(bitemporal.WithValidTime(context.Background(), bitemporal.AsTime("2023-06-12")), bitemporal.AsTime("2024-01-03"))
+			periods, err := temporalDB.TemporalJoin(ctx, bitemporal.Join{Left: "employees", Right: "employees"})
+			if err != nil {
+				t.Fatalf("Failed to join: %v", err)
+			}
+			if len(periods) != 1 || !periods[0].TxnOpen.Equal(bitemporal.AsTime("2024-01-02 09:00:00")) {
+				t.Errorf("Expected the saved employee joined with itself, got %v", periods)
+			}
+
+			// Jane is assigned to d005 a year before it exists
+			if _, err = model.NewDepartmentRepository(temporalDB).Save(ctx, model.Department{DeptNo: "d005", DeptName: "Development"},
+				bitemporal.AsTime("2021-01-01"), bitemporal.EndOfTime); err != nil {
+				t.Fatal(err)
+			}
+			if _, err = model.NewDeptEmpRepository(temporalDB).Save(ctx, model.DeptEmp{EmpNo: 12345, DeptNo: "d005"},
+				bitemporal.AsTime("2020-01-15"), bitemporal.EndOfTime); err != nil {
+				t.Fatal(err)
+			}
+			violations, err := temporalDB.CheckReferences(context.Background())
+			if err != nil {
+				t.Fatalf("Failed to check references: %v", err)
+			}
+			if len(violations) != 1 || violations[0].Reference.Table != "departments" ||
+				!violations[0].ValidOpen.Equal(bitemporal.AsTime("2020-01-15")) || !violations[0].ValidClose.Equal(bitemporal.AsTime("2021-01-01")) {
+				t.Errorf("Expected d005 to be missing from 2020-01-15 to 2021-01-01, got %v", violations)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package bitemporal
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Overlap is a pair of records of one key that were believed at the same time to be valid at the same time, which
+// breaks the temporal primary key. Entity holds the intersection of both their valid and transaction periods.
+type Overlap struct {
+	Table  string         `json:"table"`
+	Key    map[string]any `json:"key"`
+	RowIDs [2]int64       `json:"row_ids"`
+	Entity
+}
+
+func (o Overlap) String() string {
+	return fmt.Sprintf("%s %v rows %d and %d overlap %s", o.Table, o.Key, o.RowIDs[0], o.RowIDs[1], o.Entity)
+}
+
+// OverlapError is returned by OverlapCheck when a write leaves overlapping records
+type OverlapError struct {
+	Overlaps []Overlap
+}
+
+func (e *OverlapError) Error() string {
+	if len(e.Overlaps) == 1 {
+		return "temporal primary key violated: " + e.Overlaps[0].String()
+	}
+	return fmt.Sprintf("temporal primary key violated %d times, first: %s", len(e.Overlaps), e.Overlaps[0])
+}
+
+// Audit reports every pair of records of a registered table sharing a key whose valid and transaction periods
+// both overlap, over the whole transaction history. See CheckReferences for the references between tables. Records
+// are told apart by their SQLite rowid, other dialects return ErrUnsupported. Tables without key columns have no
+// temporal primary key to audit and are an error.
+func (repo *TemporalDB) Audit(ctx context.Context) ([]Overlap, error) {
+	if err := repo.requireSQLite("auditing"); err != nil {
+		return nil, err
+	}
+	var overlaps []Overlap
+	for _, table := range repo.temporalTables {
+		found, err := repo.queryOverlaps(ctx, repo.db, table, nil, QueryFragment{})
+		if err != nil {
+			return nil, err
+		}
+		overlaps = append(overlaps, found...)
+	}
+	return overlaps, nil
+}
+
+// OverlapCheck is a PreCommitHook rejecting writes that leave the current records of a written key overlapping in
+// valid time, with an *OverlapError. Like Audit it is only supported by SQLite.
+func OverlapCheck(ctx context.Context, repo *TemporalDB, tx *sql.Tx, write Write) error {
+	table, ok := repo.table(write.Table)
+	if !ok {
+		return nil
+	}
+	if err := repo.requireSQLite("checking overlaps"); err != nil {
+		return err
+	}
+
+	current := QueryFragment{
+		Query: `a.txn_open <= @txn_moment AND a.txn_close > @txn_moment
+  AND b.txn_open <= @txn_moment AND b.txn_close > @txn_moment`,
+		ArgMap: map[string]any{"txn_moment": write.TxnMoment},
+	}
+	var overlaps []Overlap
+	for _, key := range write.Keys {
+		found, err := repo.queryOverlaps(ctx, tx, table, key, current)
+		if err != nil {
+			return err
+		}
+		overlaps = append(overlaps, found...)
+	}
+
+	if len(overlaps) > 0 {
+		return &OverlapError{Overlaps: overlaps}
+	}
+	return nil
+}
+
+// queryOverlaps self-joins the records of table matching key, filter may further restrict the pair a and b
+func (repo *TemporalDB) queryOverlaps(ctx context.Context, q querier, table Table, key map[string]any, filter QueryFragment) ([]Overlap, error) {
+	if len(table.Keys) == 0 {
+		return nil, fmt.Errorf("table %q has no key columns", table.Name)
+	}
+	args := maps.Clone(filter.ArgMap)
+	if args == nil {
+		args = make(map[string]any)
+	}
+	conditions := make([]string, 0, len(table.Keys)+len(key)+1)
+	for _, column := range table.Keys {
+		conditions = append(conditions, fmt.Sprintf("a.%s = b.%s", column, column))
+	}
+	for _, column := range slices.Sorted(maps.Keys(key)) {
+		conditions = append(conditions, fmt.Sprintf("a.%s = @key_%s", column, column))
+		args["key_"+column] = key[column]
+	}
+	if filter.Query != "" {
+		conditions = append(conditions, filter.Query)
+	}
+
+	query := fmt.Sprintf(`SELECT a.rowid, b.rowid, a.%s,
+    MAX(a.valid_open, b.valid_open), MIN(a.valid_close, b.valid_close),
+    MAX(a.txn_open, b.txn_open), MIN(a.txn_close, b.txn_close)
+FROM %s a
+JOIN %s b ON a.rowid < b.rowid
+WHERE %s
+  AND a.valid_open < b.valid_close AND b.valid_open < a.valid_close
+  AND a.txn_open < b.txn_close AND b.txn_open < a.txn_close
+ORDER BY a.rowid, b.rowid`,
+		strings.Join(table.Keys, ", a."), table.Name, table.Name, strings.Join(conditions, "\n  AND "))
+
+	query, bound := repo.bind(QueryFragment{query, args})
+	rows, err := q.QueryContext(ctx, query, bound...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var overlaps []Overlap
+	for rows.Next() {
+		overlap := Overlap{Table: table.Name, Key: make(map[string]any, len(table.Keys))}
+		keyValues := make([]any, len(table.Keys))
+		moments := make([]any, 4)
+		targets := []any{&overlap.RowIDs[0], &overlap.RowIDs[1]}
+		for i := range keyValues {
+			targets = append(targets, &keyValues[i])
+		}
+		for i := range moments {
+			targets = append(targets, &moments[i])
+		}
+		if err = rows.Scan(targets...); err != nil {
+			return nil, err
+		}
+
+		for i, column := range table.Keys {
+			overlap.Key[column] = keyValues[i]
+		}
+		for i, moment := range []*time.Time{&overlap.ValidOpen, &overlap.ValidClose, &overlap.TxnOpen, &overlap.TxnClose} {
+			if *moment, err = asMoment(moments[i]); err != nil {
+				return nil, err
+			}
+		}
+		overlaps = append(overlaps, overlap)
+	}
+	return overlaps, rows.Err()
+}
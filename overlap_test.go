@@ -0,0 +1,98 @@
+package bitemporal_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pborges/bitemporal"
+	"github.com/pborges/bitemporal/model"
+)
+
+func TestAudit(t *testing.T) {
+	temporalDB, _, cleanup := createTemporalTestDB(t)
+	defer cleanup()
+
+	// the test data never closed the first Johnson record when the marriage date was corrected
+	overlaps, err := temporalDB.Audit(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(overlaps) != 1 {
+		t.Fatalf("Expected a single overlap, got %v", overlaps)
+	}
+	overlap := overlaps[0]
+	if overlap.Table != "employees" || overlap.Key["emp_no"] != int64(12345) {
+		t.Errorf("Expected the overlap to be on employee 12345, got %v", overlap)
+	}
+	if !overlap.ValidOpen.Equal(bitemporal.AsTime("2023-06-15")) || !overlap.ValidClose.Equal(bitemporal.EndOfTime) {
+		t.Errorf("Expected the overlap to be valid from 2023-06-15, got %s", overlap.Entity)
+	}
+	if !overlap.TxnOpen.Equal(bitemporal.AsTime("2023-08-15 10:15:00")) || !overlap.TxnClose.Equal(bitemporal.EndOfTime) {
+		t.Errorf("Expected the overlap to be believed from 2023-08-15 10:15:00, got %s", overlap.Entity)
+	}
+}
+
+func TestOverlapCheck(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+	_, err := db.Exec(`INSERT INTO titles (emp_no, title, valid_open, valid_close, txn_open, txn_close)
+VALUES (20000, 'Engineer', '2000-01-01', '2010-01-01', '2025-08-23 08:55:49', '9999-12-31 23:59:59'),
+       (20000, 'Senior Engineer', '2005-01-01', '9999-12-31 23:59:59', '2025-08-23 08:55:49', '9999-12-31 23:59:59')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	temporalDB, err := bitemporal.NewTemporalDB(db, bitemporal.WithPreCommitHook(bitemporal.OverlapCheck))
+	if err != nil {
+		t.Fatal(err)
+	}
+	titles := model.NewTitleRepository(temporalDB)
+	ctx := context.Background()
+
+	// the promotion leaves the overlap between the first two titles in place
+	var overlapErr *bitemporal.OverlapError
+	_, err = titles.Save(ctx, model.Title{EmpNo: 20000, Title: "Staff Engineer"}, bitemporal.AsTime("2020-01-01"), bitemporal.EndOfTime)
+	if !errors.As(err, &overlapErr) {
+		t.Fatalf("Expected an OverlapError, got %v", err)
+	}
+	if len(overlapErr.Overlaps) != 1 || !overlapErr.Overlaps[0].ValidOpen.Equal(bitemporal.AsTime("2005-01-01")) ||
+		!overlapErr.Overlaps[0].ValidClose.Equal(bitemporal.AsTime("2010-01-01")) {
+		t.Errorf("Expected the titles to overlap from 2005-01-01 to 2010-01-01, got %v", overlapErr.Overlaps)
+	}
+
+	// the update segments of overlapping records overlap too, the timeline has to be cleared before it is rewritten
+	if _, err = titles.Delete(ctx, bitemporal.AsTime("2000-01-01"), bitemporal.EndOfTime, 20000); err != nil {
+		t.Fatalf("Expected the delete to be accepted, got %v", err)
+	}
+	if _, err = titles.Save(ctx, model.Title{EmpNo: 20000, Title: "Staff Engineer"}, bitemporal.AsTime("2000-01-01"), bitemporal.EndOfTime); err != nil {
+		t.Fatalf("Expected the write to be accepted, got %v", err)
+	}
+
+	// the overlap remains in the transaction history, but is no longer current
+	overlaps, err := temporalDB.Audit(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(overlaps) != 1 || overlaps[0].TxnClose.Equal(bitemporal.EndOfTime) {
+		t.Errorf("Expected a single overlap closed in transaction time, got %v", overlaps)
+	}
+}
+
+func TestAuditKeylessTable(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	registry, err := bitemporal.NewRegistry(bitemporal.Table{Name: "titles", Columns: []string{"emp_no", "title"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	temporalDB, err := bitemporal.NewTemporalDB(db, bitemporal.WithRegistry(registry))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = temporalDB.Audit(context.Background()); err == nil || !strings.Contains(err.Error(), `"titles"`) {
+		t.Errorf("Expected an error naming the keyless table, got %v", err)
+	}
+}
@@ -0,0 +1,135 @@
+package bitemporal
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Postgres is the PostgreSQL Dialect storing the period columns as timestamptz, see PostgresDialect
+var Postgres = PostgresDialect{}
+
+// PostgresDialect binds @name parameters as $n placeholders cast to the type of their argument, and stores moments as
+// timestamptz. Queries, update windows and TemporalJoin are portable. Batch update windows, Audit, OverlapCheck,
+// MigratePeriods and Aggregate sampled at intervals return ErrUnsupported.
+type PostgresDialect struct {
+	// Ranges adds tstzrange columns over the valid and transaction periods to the DDL, with an exclusion constraint
+	// rejecting records of the same key whose periods overlap in both dimensions
+	Ranges bool
+}
+
+func (PostgresDialect) Name() string {
+	return "postgres"
+}
+
+// Bind numbers the parameters by first use, a repeated name reuses its placeholder
+func (PostgresDialect) Bind(query string, args map[string]any) (string, []any) {
+	query, bound := bindPositional(query, args, false, func(name string, n int) string {
+		return "$" + strconv.Itoa(n) + postgresCast(args[name])
+	})
+	for i, v := range bound {
+		if t, ok := v.(time.Time); ok {
+			bound[i] = t.UTC()
+		}
+	}
+	return query, bound
+}
+
+// postgresCast types a placeholder from its argument, Postgres cannot infer it where a parameter is selected
+func postgresCast(v any) string {
+	switch v.(type) {
+	case time.Time:
+		return "::timestamptz"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return "::bigint"
+	case float32, float64:
+		return "::double precision"
+	case string:
+		return "::text"
+	case bool:
+		return "::boolean"
+	case []byte:
+		return "::bytea"
+	}
+	return ""
+}
+
+func (PostgresDialect) Timestamp(t time.Time) string {
+	return "TIMESTAMPTZ '" + t.UTC().Format("2006-01-02 15:04:05.999999-07") + "'"
+}
+
+func (d PostgresDialect) Infinity() string {
+	return d.Timestamp(EndOfTime)
+}
+
+func (PostgresDialect) ViewName(table string) string {
+	return table + "$"
+}
+
+func (PostgresDialect) Session() []string {
+	return []string{"SET TIME ZONE 'UTC'"}
+}
+
+func (PostgresDialect) ColumnsQuery() string {
+	return "SELECT column_name FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = @table ORDER BY ordinal_position"
+}
+
+var createTableName = regexp.MustCompile(`(?i)^CREATE TABLE (?:IF NOT EXISTS )?(\w+)`)
+
+// DDL translates the SQLite schema, e.g. sql/schema.sql, to PostgreSQL. With Ranges every table gets the generated
+// valid_period and txn_period columns, and the tables of the registry with keys get their exclusion constraint.
+func (d PostgresDialect) DDL(schema string, registry *Registry) (string, error) {
+	schema = sqliteIdentity.ReplaceAllString(schema, "BIGINT${1}GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY")
+	schema = sqliteInteger.ReplaceAllString(schema, "BIGINT")
+	schema = sqliteDatetime.ReplaceAllString(schema, "TIMESTAMPTZ")
+	schema = sqliteNow.ReplaceAllString(schema, "DEFAULT CURRENT_TIMESTAMP")
+	schema, err := replaceMoments(schema, d.Timestamp)
+	if err != nil || !d.Ranges {
+		return schema, err
+	}
+
+	var tables []Table
+	if registry != nil {
+		tables = registry.Tables()
+	}
+	lines := strings.Split(schema, "\n")
+	out := make([]string, 0, len(lines)+1)
+	out = append(out, "CREATE EXTENSION IF NOT EXISTS btree_gist;", "")
+	table := ""
+	for _, line := range lines {
+		if match := createTableName.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			table = match[1]
+		}
+		if table == "" || !strings.HasPrefix(strings.TrimSpace(line), ")") {
+			out = append(out, line)
+			continue
+		}
+
+		// the last column definition, skipping comments, needs a comma before the period columns
+		for i := len(out) - 1; i >= 0; i-- {
+			if trimmed := strings.TrimSpace(out[i]); trimmed != "" && !strings.HasPrefix(trimmed, "--") {
+				out[i] += ","
+				break
+			}
+		}
+		definitions := []string{
+			"valid_period     TSTZRANGE GENERATED ALWAYS AS (tstzrange(valid_open, valid_close)) STORED",
+			"txn_period       TSTZRANGE GENERATED ALWAYS AS (tstzrange(txn_open, txn_close)) STORED",
+		}
+		if i := slices.IndexFunc(tables, func(t Table) bool { return t.Name == table }); i >= 0 && len(tables[i].Keys) > 0 {
+			exclusion := make([]string, 0, len(tables[i].Keys)+2)
+			for _, key := range tables[i].Keys {
+				exclusion = append(exclusion, key+" WITH =")
+			}
+			exclusion = append(exclusion, "valid_period WITH &&", "txn_period WITH &&")
+			definitions = append(definitions, fmt.Sprintf("CONSTRAINT %s_no_overlap EXCLUDE USING gist (%s)", table, strings.Join(exclusion, ", ")))
+		}
+		out = append(out, "    "+strings.Join(definitions, ",\n    "))
+		out = append(out, line)
+		table = ""
+	}
+	return strings.Join(out, "\n"), nil
+}
@@ -0,0 +1,255 @@
+package bitemporal
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Reference declares that the values of Columns must identify a record of Table at every valid moment the
+// referencing record is valid
+type Reference struct {
+	Columns []string `json:"columns"`
+	Table   string   `json:"table"`
+	// ParentColumns are the columns of Table matched against Columns, in the same order
+	ParentColumns []string `json:"parent_columns"`
+}
+
+func (r Reference) String() string {
+	return fmt.Sprintf("(%s) references %s(%s)", strings.Join(r.Columns, ", "), r.Table, strings.Join(r.ParentColumns, ", "))
+}
+
+// addReference adds column to the reference to parent, given as "table" or "table.column"
+func (t *Table) addReference(column, parent string) {
+	parentTable, parentColumn, ok := strings.Cut(parent, ".")
+	if !ok {
+		parentColumn = column
+	}
+	for i := range t.References {
+		if t.References[i].Table == parentTable {
+			t.References[i].Columns = append(t.References[i].Columns, column)
+			t.References[i].ParentColumns = append(t.References[i].ParentColumns, parentColumn)
+			return
+		}
+	}
+	t.References = append(t.References, Reference{
+		Columns:       []string{column},
+		Table:         parentTable,
+		ParentColumns: []string{parentColumn},
+	})
+}
+
+// ReferenceViolation is a valid-time interval where a record has no covering record in the table it references
+type ReferenceViolation struct {
+	Table      string         `json:"table"`
+	Values     map[string]any `json:"values"`
+	Reference  Reference      `json:"reference"`
+	ValidOpen  time.Time      `json:"valid_open"`
+	ValidClose time.Time      `json:"valid_close"`
+}
+
+func (v ReferenceViolation) String() string {
+	values := make([]string, len(v.Reference.Columns))
+	for i, column := range v.Reference.Columns {
+		values[i] = fmt.Sprintf("%s=%v", column, v.Values[column])
+	}
+	return fmt.Sprintf("%s{%s} has no %s from %s to %s", v.Table, strings.Join(values, ", "), v.Reference.Table,
+		v.ValidOpen.Format(time.DateTime), v.ValidClose.Format(time.DateTime))
+}
+
+// ReferenceError is returned by ReferenceCheck when a write leaves references uncovered
+type ReferenceError struct {
+	Violations []ReferenceViolation
+}
+
+func (e *ReferenceError) Error() string {
+	if len(e.Violations) == 1 {
+		return "temporal reference violated: " + e.Violations[0].String()
+	}
+	return fmt.Sprintf("temporal references violated %d times, first: %s", len(e.Violations), e.Violations[0])
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// CheckReferences reports every valid-time interval where a record of a registered table is not covered by the
+// records it references. Records are checked as known at the system moment of the TemporalContext, or now.
+func (repo *TemporalDB) CheckReferences(ctx context.Context) ([]ReferenceViolation, error) {
+	ctx = WithSystemMoment(WithValidTime(ctx, time.Time{}), repo.systemMoment(ctx))
+
+	var violations []ReferenceViolation
+	for _, table := range repo.temporalTables {
+		for _, ref := range table.References {
+			found, err := repo.referenceViolations(ctx, repo.db, table, ref, nil)
+			if err != nil {
+				return nil, err
+			}
+			violations = append(violations, found...)
+		}
+	}
+	return violations, nil
+}
+
+// ReferenceCheck is a PreCommitHook rejecting writes that leave a reference uncovered, both from the written records
+// to the tables they reference and from the records referencing the written keys, with a *ReferenceError
+func ReferenceCheck(ctx context.Context, repo *TemporalDB, tx *sql.Tx, write Write) error {
+	ctx = WithSystemMoment(WithValidTime(ctx, time.Time{}), write.TxnMoment)
+	if len(write.Keys) == 0 {
+		return nil
+	}
+
+	var violations []ReferenceViolation
+	check := func(table Table, ref Reference, keys []map[string]any) error {
+		found, err := repo.referenceViolations(ctx, tx, table, ref, keys)
+		violations = append(violations, found...)
+		return err
+	}
+
+	if table, ok := repo.table(write.Table); ok {
+		for _, ref := range table.References {
+			if err := check(table, ref, write.Keys); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, table := range repo.temporalTables {
+		for _, ref := range table.References {
+			if ref.Table != write.Table {
+				continue
+			}
+			// records referencing the keys, or every record when a key does not cover the referenced columns
+			keys := make([]map[string]any, 0, len(write.Keys))
+			for _, key := range write.Keys {
+				filter := make(map[string]any, len(ref.Columns))
+				for i, column := range ref.ParentColumns {
+					if value, ok := key[column]; ok {
+						filter[ref.Columns[i]] = value
+					}
+				}
+				if len(filter) != len(ref.Columns) {
+					keys = nil
+					break
+				}
+				keys = append(keys, filter)
+			}
+			if err := check(table, ref, keys); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		return &ReferenceError{Violations: violations}
+	}
+	return nil
+}
+
+type validInterval struct {
+	open, close time.Time
+}
+
+// referenceViolations checks the records of table matching any of keys, or every record when there are none,
+// against the referenced table in a single query. A gap opens where a record starts or a covering parent record
+// ends while no parent record covers that moment, and closes where the next parent record starts or the record ends.
+func (repo *TemporalDB) referenceViolations(ctx context.Context, q querier, table Table, ref Reference, keys []map[string]any) ([]ReferenceViolation, error) {
+	args := make(map[string]any)
+	var groups []string
+	for i, key := range keys {
+		var where []string
+		for _, column := range slices.Sorted(maps.Keys(key)) {
+			param := fmt.Sprintf("key%d_%s", i, column)
+			where = append(where, fmt.Sprintf("c.%s = @%s", column, param))
+			args[param] = key[column]
+		}
+		groups = append(groups, "("+strings.Join(where, " AND ")+")")
+	}
+	predicate := "TRUE"
+	if len(groups) > 0 {
+		predicate = "(" + strings.Join(groups, " OR ") + ")"
+	}
+
+	join := make([]string, len(ref.Columns))
+	for i := range ref.Columns {
+		join[i] = fmt.Sprintf("c.%s = p.%s", ref.Columns[i], ref.ParentColumns[i])
+	}
+	columns := "c." + strings.Join(table.Columns, ", c.")
+	parent := repo.View(ref.Table, "p")
+	child := repo.View(table.Name, "c")
+
+	gaps, err := repo.queryIntervals(ctx, q, QueryFragment{
+		Query: fmt.Sprintf(`SELECT %[1]s, c.gap_open, COALESCE((
+    SELECT MIN(p.valid_open) FROM %[2]s WHERE %[4]s AND p.valid_open > c.gap_open AND p.valid_open < c.valid_close
+), c.valid_close)
+FROM (
+    SELECT %[1]s, c.valid_close, c.valid_open gap_open FROM %[3]s WHERE %[5]s
+    UNION
+    SELECT %[1]s, c.valid_close, p.valid_close FROM %[3]s JOIN %[2]s ON %[4]s
+    WHERE %[5]s AND p.valid_close > c.valid_open AND p.valid_close < c.valid_close
+) c
+WHERE NOT EXISTS (SELECT 1 FROM %[2]s WHERE %[4]s AND p.valid_open <= c.gap_open AND p.valid_close > c.gap_open)
+ORDER BY c.gap_open`, columns, parent, child, strings.Join(join, " AND "), predicate),
+		ArgMap: args,
+	}, len(table.Columns))
+	if err != nil {
+		return nil, err
+	}
+
+	violations := make([]ReferenceViolation, len(gaps))
+	for i, gap := range gaps {
+		violations[i] = ReferenceViolation{
+			Table:      table.Name,
+			Values:     make(map[string]any, len(table.Columns)),
+			Reference:  ref,
+			ValidOpen:  gap.open,
+			ValidClose: gap.close,
+		}
+		for j, column := range table.Columns {
+			violations[i].Values[column] = gap.values[j]
+		}
+	}
+	return violations, nil
+}
+
+type intervalRecord struct {
+	values []any
+	validInterval
+}
+
+func (repo *TemporalDB) queryIntervals(ctx context.Context, q querier, fragment QueryFragment, width int) ([]intervalRecord, error) {
+	fragment = repo.prepareQuery(ctx, fragment)
+	query, args := repo.bind(fragment)
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []intervalRecord
+	for rows.Next() {
+		record := make([]any, width+2)
+		ptrs := make([]any, len(record))
+		for i := range record {
+			ptrs[i] = &record[i]
+		}
+		if err = rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+
+		r := intervalRecord{values: record[:width]}
+		if r.open, err = asMoment(record[width]); err != nil {
+			return nil, err
+		}
+		if r.close, err = asMoment(record[width+1]); err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
@@ -0,0 +1,148 @@
+package bitemporal_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/pborges/bitemporal"
+	"github.com/pborges/bitemporal/model"
+)
+
+func TestReferences(t *testing.T) {
+	table := bitemporal.MustTableOf[model.DeptEmp]()
+	expected := []bitemporal.Reference{
+		{Columns: []string{"emp_no"}, Table: "employees", ParentColumns: []string{"emp_no"}},
+		{Columns: []string{"dept_no"}, Table: "departments", ParentColumns: []string{"dept_no"}},
+	}
+	if !reflect.DeepEqual(table.References, expected) {
+		t.Errorf("Expected references %v, got %v", expected, table.References)
+	}
+
+	temporalDB, _, cleanup := createTemporalTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	departments := model.NewDepartmentRepository(temporalDB)
+	deptEmp := model.NewDeptEmpRepository(temporalDB)
+
+	if _, err := departments.Save(ctx, model.Department{DeptNo: "d005", DeptName: "Development"},
+		bitemporal.AsTime("2021-01-01"), bitemporal.EndOfTime); err != nil {
+		t.Fatal(err)
+	}
+	// Jane joined on 2020-01-15, a year before d005 existed
+	if _, err := deptEmp.Save(ctx, model.DeptEmp{EmpNo: 12345, DeptNo: "d005"},
+		bitemporal.AsTime("2020-01-15"), bitemporal.EndOfTime); err != nil {
+		t.Fatal(err)
+	}
+
+	violations, err := temporalDB.CheckReferences(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(violations) != 1 {
+		t.Fatalf("Expected a single violation, got %v", violations)
+	}
+	violation := violations[0]
+	if violation.Reference.Table != "departments" ||
+		!violation.ValidOpen.Equal(bitemporal.AsTime("2020-01-15")) || !violation.ValidClose.Equal(bitemporal.AsTime("2021-01-01")) {
+		t.Errorf("Expected d005 to be missing from 2020-01-15 to 2021-01-01, got %s", violation)
+	}
+}
+
+func TestReferenceCheck(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+	if _, err := db.Exec(validTimeData); err != nil {
+		t.Fatal(err)
+	}
+	temporalDB, err := bitemporal.NewTemporalDB(db, bitemporal.WithPreCommitHook(bitemporal.ReferenceCheck))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	// the test data predates the canonical encoding
+	if _, err = temporalDB.MigratePeriods(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	departments := model.NewDepartmentRepository(temporalDB)
+	deptEmp := model.NewDeptEmpRepository(temporalDB)
+
+	if _, err = departments.Save(ctx, model.Department{DeptNo: "d005", DeptName: "Development"},
+		bitemporal.AsTime("2021-01-01"), bitemporal.EndOfTime); err != nil {
+		t.Fatal(err)
+	}
+
+	var refErr *bitemporal.ReferenceError
+	_, err = deptEmp.Save(ctx, model.DeptEmp{EmpNo: 12345, DeptNo: "d005"}, bitemporal.AsTime("2020-01-15"), bitemporal.EndOfTime)
+	if !errors.As(err, &refErr) {
+		t.Fatalf("Expected a ReferenceError for an assignment before d005 existed, got %v", err)
+	}
+	t.Log(err)
+
+	if _, err = deptEmp.Save(ctx, model.DeptEmp{EmpNo: 12345, DeptNo: "d005"}, bitemporal.AsTime("2021-01-01"), bitemporal.EndOfTime); err != nil {
+		t.Fatalf("Expected an assignment covered by both parents to be accepted, got %v", err)
+	}
+	if _, err = deptEmp.Save(ctx, model.DeptEmp{EmpNo: 99999, DeptNo: "d005"}, bitemporal.AsTime("2021-01-01"), bitemporal.EndOfTime); !errors.As(err, &refErr) {
+		t.Errorf("Expected a ReferenceError for an unknown employee, got %v", err)
+	}
+
+	// closing the department while Jane is assigned to it leaves her assignment dangling
+	_, err = departments.Terminate(ctx, bitemporal.AsTime("2024-01-01"), "d005")
+	if !errors.As(err, &refErr) {
+		t.Fatalf("Expected a ReferenceError when terminating a referenced department, got %v", err)
+	}
+	if len(refErr.Violations) != 1 || refErr.Violations[0].Table != "dept_emp" ||
+		!refErr.Violations[0].ValidOpen.Equal(bitemporal.AsTime("2024-01-01")) {
+		t.Errorf("Expected the assignment to be uncovered from 2024-01-01, got %v", refErr.Violations)
+	}
+
+	// the rejected writes were rolled back
+	violations, err := temporalDB.CheckReferences(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(violations) != 0 {
+		t.Errorf("Expected no violations, got %v", violations)
+	}
+}
+
+func TestReferenceCheckBatch(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+	if _, err := db.Exec(validTimeData); err != nil {
+		t.Fatal(err)
+	}
+	temporalDB, err := bitemporal.NewTemporalDB(db, bitemporal.WithPreCommitHook(bitemporal.ReferenceCheck))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err = temporalDB.MigratePeriods(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = model.NewDepartmentRepository(temporalDB).Save(ctx, model.Department{DeptNo: "d005", DeptName: "Development"},
+		bitemporal.AsTime("2021-01-01"), bitemporal.EndOfTime); err != nil {
+		t.Fatal(err)
+	}
+
+	// every key of the batch is checked, only the unknown employee is uncovered
+	var refErr *bitemporal.ReferenceError
+	_, err = temporalDB.ApplyBatchUpdateWindow(ctx, bitemporal.BatchUpdateWindow{
+		Table:     "dept_emp",
+		Select:    []string{"emp_no", "dept_no"},
+		FilterBy:  []string{"emp_no", "dept_no"},
+		Keys:      []map[string]any{{"emp_no": 12345, "dept_no": "d005"}, {"emp_no": 99999, "dept_no": "d005"}},
+		ValidFrom: bitemporal.AsTime("2021-01-01"),
+		ValidTo:   bitemporal.EndOfTime,
+	})
+	if !errors.As(err, &refErr) {
+		t.Fatalf("Expected a ReferenceError for a batch assigning an unknown employee, got %v", err)
+	}
+	if len(refErr.Violations) != 1 || refErr.Violations[0].Reference.Table != "employees" ||
+		refErr.Violations[0].Values["emp_no"] != int64(99999) {
+		t.Errorf("Expected only employee 99999 to be missing, got %v", refErr.Violations)
+	}
+}
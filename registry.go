@@ -0,0 +1,157 @@
+package bitemporal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// temporalColumns are required on every registered table
+var temporalColumns = []string{"valid_open", "valid_close", "txn_open", "txn_close"}
+
+// DefaultRegistry is used by NewTemporalDB unless WithRegistry is given, models register themselves here from init.
+// It is not validated against the database, importing a model must not break databases lacking its table.
+var DefaultRegistry = &Registry{}
+
+// Schema holds tables known to every TemporalDB using DefaultRegistry.
+//
+// Deprecated: register tables with DefaultRegistry.MustRegister, or pass a Registry with WithRegistry.
+var Schema []Table
+
+// Registry is the set of bitemporal tables a TemporalDB knows about
+type Registry struct {
+	mu     sync.Mutex
+	tables []Table
+}
+
+// NewRegistry registers every table given, see Register
+func NewRegistry(tables ...Table) (*Registry, error) {
+	r := &Registry{}
+	for _, table := range tables {
+		if err := r.Register(table); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Register adds a table, it fails if the table is malformed or already registered
+func (r *Registry) Register(table Table) error {
+	if table.Name == "" {
+		return errors.New("table has no name")
+	}
+	if len(table.Columns) == 0 {
+		return fmt.Errorf("table %q has no columns", table.Name)
+	}
+	for _, key := range table.Keys {
+		if !slices.Contains(table.Columns, key) {
+			return fmt.Errorf("table %q key %q is not one of its columns", table.Name, key)
+		}
+	}
+	for _, ref := range table.References {
+		if len(ref.Columns) == 0 || len(ref.Columns) != len(ref.ParentColumns) {
+			return fmt.Errorf("table %q reference %s does not match its columns to the parent", table.Name, ref)
+		}
+		for _, column := range ref.Columns {
+			if !slices.Contains(table.Columns, column) {
+				return fmt.Errorf("table %q reference column %q is not one of its columns", table.Name, column)
+			}
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if slices.ContainsFunc(r.tables, func(t Table) bool { return t.Name == table.Name }) {
+		return fmt.Errorf("table %q is already registered", table.Name)
+	}
+	r.tables = append(r.tables, table)
+	return nil
+}
+
+// MustRegister is like Register but panics, it is meant for init functions
+func (r *Registry) MustRegister(table Table) {
+	if err := r.Register(table); err != nil {
+		panic(err)
+	}
+}
+
+// Tables returns a copy of the registered tables in registration order
+func (r *Registry) Tables() []Table {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.tables)
+}
+
+// defaultTables is DefaultRegistry followed by the tables appended to the deprecated Schema
+func defaultTables() []Table {
+	tables := DefaultRegistry.Tables()
+	for _, table := range Schema {
+		if !slices.ContainsFunc(tables, func(t Table) bool { return t.Name == table.Name }) {
+			tables = append(tables, table)
+		}
+	}
+	return tables
+}
+
+// Validate checks every registered table exists in the SQLite database with its columns and the temporal columns,
+// and that every table referenced is registered with the referenced columns
+func (r *Registry) Validate(ctx context.Context, db *sql.DB) error {
+	return r.ValidateWith(ctx, db, SQLite)
+}
+
+// ValidateWith is Validate for a database of any Dialect
+func (r *Registry) ValidateWith(ctx context.Context, db *sql.DB, dialect Dialect) error {
+	var errs []error
+	tables := r.Tables()
+	for _, table := range tables {
+		for _, ref := range table.References {
+			i := slices.IndexFunc(tables, func(t Table) bool { return t.Name == ref.Table })
+			if i < 0 {
+				errs = append(errs, fmt.Errorf("table %q references %q which is not registered", table.Name, ref.Table))
+				continue
+			}
+			for _, column := range ref.ParentColumns {
+				if !slices.Contains(tables[i].Columns, column) {
+					errs = append(errs, fmt.Errorf("table %q references column %q which %q does not have", table.Name, column, ref.Table))
+				}
+			}
+		}
+
+		columns, err := tableColumns(ctx, db, dialect, table.Name)
+		if err != nil {
+			return err
+		}
+		if len(columns) == 0 {
+			errs = append(errs, fmt.Errorf("table %q does not exist", table.Name))
+			continue
+		}
+		for _, column := range slices.Concat(table.Columns, temporalColumns) {
+			if !slices.Contains(columns, column) {
+				errs = append(errs, fmt.Errorf("table %q has no column %q", table.Name, column))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func tableColumns(ctx context.Context, db *sql.DB, dialect Dialect, table string) ([]string, error) {
+	query, args := dialect.Bind(dialect.ColumnsQuery(), map[string]any{"table": table})
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err = rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	return columns, rows.Err()
+}
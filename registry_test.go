@@ -0,0 +1,109 @@
+package bitemporal_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/pborges/bitemporal"
+	"github.com/pborges/bitemporal/model"
+)
+
+func TestRegistry(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	salaries := bitemporal.MustTableOf[model.Salary]()
+	registry, err := bitemporal.NewRegistry(salaries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = registry.Register(salaries); err == nil {
+		t.Error("Expected registering a table twice to fail")
+	}
+	if err = registry.Register(bitemporal.Table{Name: "titles", Columns: []string{"title"}, Keys: []string{"emp_no"}}); err == nil {
+		t.Error("Expected registering a key that is not a column to fail")
+	}
+
+	temporalDB, err := bitemporal.NewTemporalDB(db, bitemporal.WithRegistry(registry))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// only the registered tables get a temporal view
+	if err = queryTable(temporalDB, "salaries$"); err != nil {
+		t.Errorf("Expected salaries$ to be queryable: %v", err)
+	}
+	if err = queryTable(temporalDB, "employees$"); err == nil {
+		t.Error("Expected employees$ to be unknown to a registry without employees")
+	}
+
+	// a second database can be opened with a different set of tables
+	other, err := bitemporal.NewRegistry(bitemporal.MustTableOf[model.Employee]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherDB, err := bitemporal.NewTemporalDB(db, bitemporal.WithRegistry(other))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = queryTable(otherDB, "employees$"); err != nil {
+		t.Errorf("Expected employees$ to be queryable: %v", err)
+	}
+
+	// the registry is validated against the live database
+	invalid, err := bitemporal.NewRegistry(
+		bitemporal.Table{Name: "salaries", Columns: []string{"emp_no", "bonus"}, Keys: []string{"emp_no"}},
+		bitemporal.Table{Name: "bonuses", Columns: []string{"emp_no"}, Keys: []string{"emp_no"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = invalid.Validate(context.Background(), db); err == nil {
+		t.Error("Expected validation to fail for a missing column and table")
+	} else {
+		t.Log(err)
+	}
+	if _, err = bitemporal.NewTemporalDB(db, bitemporal.WithRegistry(invalid)); err == nil {
+		t.Error("Expected NewTemporalDB to reject an invalid registry")
+	}
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	db, err := sql.Open(bitemporal.SQLiteDriver, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	// the models registered from init are not required to exist in every database
+	if _, err = bitemporal.NewTemporalDB(db); err != nil {
+		t.Errorf("Expected NewTemporalDB to accept a database without the model tables, got %v", err)
+	}
+	if _, err = bitemporal.NewTemporalDB(db, bitemporal.WithRegistry(bitemporal.DefaultRegistry)); err == nil {
+		t.Error("Expected an explicit registry to be validated")
+	}
+
+	if _, err = db.Exec("CREATE TABLE legacy (id INTEGER, valid_open DATETIME, valid_close DATETIME, txn_open DATETIME, txn_close DATETIME)"); err != nil {
+		t.Fatal(err)
+	}
+	bitemporal.Schema = append(bitemporal.Schema, bitemporal.Table{Name: "legacy", Columns: []string{"id"}, Keys: []string{"id"}})
+	defer func() { bitemporal.Schema = nil }()
+	temporalDB, err := bitemporal.NewTemporalDB(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = queryTable(temporalDB, "legacy$"); err != nil {
+		t.Errorf("Expected tables appended to Schema to be queryable: %v", err)
+	}
+}
+
+func queryTable(db *bitemporal.TemporalDB, table string) error {
+	rows, err := db.Query(context.Background(), "SELECT * FROM "+table, nil)
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
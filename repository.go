@@ -0,0 +1,302 @@
+package bitemporal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var entityType = reflect.TypeOf(Entity{})
+
+// TableOf derives the Table of a model from its struct tags. Columns are taken from `db:"column"` tags, key columns
+// are tagged `db:"column,key"` and the table name is the db tag of the embedded Entity. A column referencing another
+// table is tagged `db:"column,references=table"`, or `db:"column,references=table.column"` when the names differ.
+//
+//	type Salary struct {
+//		EmpNo  int64 `db:"emp_no,key"`
+//		Salary int64 `db:"salary"`
+//		bitemporal.Entity `db:"salaries"`
+//	}
+func TableOf[T any]() (Table, error) {
+	table, _, err := reflectTable(reflect.TypeFor[T]())
+	return table, err
+}
+
+// MustTableOf is like TableOf but panics if the model is not tagged correctly, it is meant for init functions
+func MustTableOf[T any]() Table {
+	table, err := TableOf[T]()
+	if err != nil {
+		panic(err)
+	}
+	return table
+}
+
+func reflectTable(t reflect.Type) (Table, []int, error) {
+	if t.Kind() != reflect.Struct {
+		return Table{}, nil, fmt.Errorf("%s is not a struct", t)
+	}
+
+	var table Table
+	var fields []int
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup("db")
+		if !ok || tag == "-" {
+			continue
+		}
+
+		if field.Anonymous && field.Type == entityType {
+			table.Name = tag
+			continue
+		}
+		if !field.IsExported() {
+			return Table{}, nil, fmt.Errorf("%s.%s is tagged but not exported", t, field.Name)
+		}
+
+		column, options, _ := strings.Cut(tag, ",")
+		table.Columns = append(table.Columns, column)
+		for _, option := range strings.Split(options, ",") {
+			if option == "key" {
+				table.Keys = append(table.Keys, column)
+			} else if parent, ok := strings.CutPrefix(option, "references="); ok {
+				table.addReference(column, parent)
+			}
+		}
+		fields = append(fields, i)
+	}
+
+	if table.Name == "" {
+		return Table{}, nil, fmt.Errorf("%s does not embed a bitemporal.Entity tagged with the table name", t)
+	}
+	if len(table.Keys) == 0 {
+		return Table{}, nil, fmt.Errorf("%s has no key columns", t)
+	}
+	return table, fields, nil
+}
+
+// Repository provides the common reads and writes for a model, see TableOf for how T must be tagged
+type Repository[T any] struct {
+	db     *TemporalDB
+	table  Table
+	fields []int
+}
+
+// NewRepository panics if T is not tagged correctly, see TableOf
+func NewRepository[T any](db *TemporalDB) *Repository[T] {
+	table, fields, err := reflectTable(reflect.TypeFor[T]())
+	if err != nil {
+		panic(err)
+	}
+	return &Repository[T]{db: db, table: table, fields: fields}
+}
+
+func (r Repository[T]) Table() Table {
+	return r.table
+}
+
+// Get returns the first record for the key visible in the TemporalContext, or sql.ErrNoRows
+func (r Repository[T]) Get(ctx context.Context, key ...any) (T, error) {
+	var m T
+	query, args, err := r.selectQuery(r.db.View(r.table.Name, ""), "valid_open, txn_open", key)
+	if err != nil {
+		return m, err
+	}
+	row := r.db.QueryRow(ctx, query, args)
+	if row.Err() != nil {
+		return m, row.Err()
+	}
+	err = row.Scan(r.scanTargets(&m)...)
+	return m, err
+}
+
+// List returns every record visible in the TemporalContext matching the leading key columns given
+func (r Repository[T]) List(ctx context.Context, key ...any) ([]T, error) {
+	return r.query(ctx, r.db.View(r.table.Name, ""), strings.Join(r.table.Keys, ", ")+", valid_open, txn_open", key)
+}
+
+// History returns every record ever written for the leading key columns given, ignoring the TemporalContext
+func (r Repository[T]) History(ctx context.Context, key ...any) ([]T, error) {
+	return r.query(ctx, r.table.Name, "txn_open, valid_open", key)
+}
+
+// Save records m as valid over [from, to), superseding whatever was known for its key in that window
+func (r Repository[T]) Save(ctx context.Context, m T, from, to time.Time) ([]T, error) {
+	return r.SaveIfVersion(ctx, m, from, to, time.Time{})
+}
+
+// SaveIfVersion is like Save but fails with ErrVersionConflict unless the key of m is still at version, see
+// TemporalDB.Version. A zero version skips the check.
+func (r Repository[T]) SaveIfVersion(ctx context.Context, m T, from, to, version time.Time) ([]T, error) {
+	v := reflect.ValueOf(m)
+	values := make(map[string]any, len(r.fields))
+	for i, column := range r.table.Columns {
+		values[column] = v.Field(r.fields[i]).Interface()
+	}
+
+	periods, err := r.db.ApplyUpdateWindow(ctx, UpdateWindow{
+		Table:     r.table.Name,
+		Select:    r.table.Columns,
+		FilterBy:  r.table.Keys,
+		Values:    values,
+		ValidFrom: from,
+		ValidTo:   to,
+
+		ExpectedVersion: version,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.fromPeriods(periods)
+}
+
+// Version returns the version of the key, see TemporalDB.Version
+func (r Repository[T]) Version(ctx context.Context, key ...any) (time.Time, error) {
+	if len(key) != len(r.table.Keys) {
+		return time.Time{}, fmt.Errorf("%s is keyed by %d columns, got %d values", r.table.Name, len(r.table.Keys), len(key))
+	}
+	values := make(map[string]any, len(key))
+	for i := range key {
+		values[r.table.Keys[i]] = key[i]
+	}
+	return r.db.Version(ctx, r.table.Name, values)
+}
+
+// Delete removes [from, to) from the valid time of the key, what was known before is kept as history.
+// The records written to preserve the valid time outside the window are returned.
+func (r Repository[T]) Delete(ctx context.Context, from, to time.Time, key ...any) ([]T, error) {
+	if len(key) != len(r.table.Keys) {
+		return nil, fmt.Errorf("%s is keyed by %d columns, got %d values", r.table.Name, len(r.table.Keys), len(key))
+	}
+
+	values := make(map[string]any, len(key))
+	for i := range key {
+		values[r.table.Keys[i]] = key[i]
+	}
+
+	periods, err := r.db.ApplyUpdateWindow(ctx, UpdateWindow{
+		Table:     r.table.Name,
+		Select:    r.table.Columns,
+		FilterBy:  r.table.Keys,
+		Values:    values,
+		ValidFrom: from,
+		ValidTo:   to,
+		Delete:    true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.fromPeriods(periods)
+}
+
+func (r Repository[T]) selectQuery(from string, orderBy string, key []any) (string, map[string]any, error) {
+	if len(key) > len(r.table.Keys) {
+		return "", nil, fmt.Errorf("%s is keyed by %d columns, got %d values", r.table.Name, len(r.table.Keys), len(key))
+	}
+
+	args := make(map[string]any, len(key))
+	filters := make([]string, len(key))
+	for i := range key {
+		filters[i] = r.table.Keys[i] + "=@" + r.table.Keys[i]
+		args[r.table.Keys[i]] = key[i]
+	}
+
+	query := fmt.Sprintf("SELECT %s, valid_open, valid_close, txn_open, txn_close FROM %s",
+		strings.Join(r.table.Columns, ", "), from)
+	if len(filters) > 0 {
+		query += " WHERE " + strings.Join(filters, " AND ")
+	}
+	return query + " ORDER BY " + orderBy, args, nil
+}
+
+func (r Repository[T]) query(ctx context.Context, from string, orderBy string, key []any) ([]T, error) {
+	query, args, err := r.selectQuery(from, orderBy, key)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.Query(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var models []T
+	for rows.Next() {
+		var m T
+		if err = rows.Scan(r.scanTargets(&m)...); err != nil {
+			return nil, err
+		}
+		models = append(models, m)
+	}
+	return models, rows.Err()
+}
+
+// scanTargets returns pointers to the columns of m followed by its temporal columns
+func (r Repository[T]) scanTargets(m *T) []any {
+	v := reflect.ValueOf(m).Elem()
+	targets := make([]any, 0, len(r.fields)+4)
+	for _, i := range r.fields {
+		targets = append(targets, v.Field(i).Addr().Interface())
+	}
+	entity := r.entity(v)
+	return append(targets, &entity.ValidOpen, &entity.ValidClose, &entity.TxnOpen, &entity.TxnClose)
+}
+
+func (r Repository[T]) entity(v reflect.Value) *Entity {
+	for i := 0; i < v.NumField(); i++ {
+		if field := v.Type().Field(i); field.Anonymous && field.Type == entityType {
+			return v.Field(i).Addr().Interface().(*Entity)
+		}
+	}
+	// reflectTable guarantees the Entity is embedded
+	panic(errors.New("bitemporal.Entity not embedded"))
+}
+
+func (r Repository[T]) fromPeriods(periods []Period) ([]T, error) {
+	models := make([]T, 0, len(periods))
+	for _, period := range periods {
+		var m T
+		v := reflect.ValueOf(&m).Elem()
+		for i, column := range r.table.Columns {
+			if err := assign(v.Field(r.fields[i]), period.Values[column]); err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", r.table.Name, column, err)
+			}
+		}
+		*r.entity(v) = period.Entity
+		models = append(models, m)
+	}
+	return models, nil
+}
+
+// assign sets dst to a value scanned by the driver, converting where the driver's type differs from the model's
+func assign(dst reflect.Value, value any) error {
+	if value == nil {
+		dst.SetZero()
+		return nil
+	}
+
+	if dst.Type() == reflect.TypeOf(time.Time{}) {
+		t, err := asMoment(value)
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	src := reflect.ValueOf(value)
+	if b, ok := value.([]byte); ok {
+		src = reflect.ValueOf(string(b))
+	}
+	switch {
+	case src.Type().AssignableTo(dst.Type()):
+		dst.Set(src)
+	case src.Type().ConvertibleTo(dst.Type()) && src.Kind() != reflect.String && dst.Kind() != reflect.String:
+		dst.Set(src.Convert(dst.Type()))
+	default:
+		return fmt.Errorf("cannot assign %v (%T) to %s", value, value, dst.Type())
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+package bitemporal_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/pborges/bitemporal"
+	"github.com/pborges/bitemporal/model"
+)
+
+func TestTableOf(t *testing.T) {
+	table, err := bitemporal.TableOf[model.Employee]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.Name != "employees" {
+		t.Errorf("Expected table employees, got %s", table.Name)
+	}
+	if !slices.Equal(table.Keys, []string{"emp_no"}) {
+		t.Errorf("Expected key emp_no, got %v", table.Keys)
+	}
+	expected := []string{"emp_no", "birth_date", "first_name", "last_name", "gender", "hire_date"}
+	if !slices.Equal(table.Columns, expected) {
+		t.Errorf("Expected columns %v, got %v", expected, table.Columns)
+	}
+
+	type untagged struct {
+		Name string `db:"name,key"`
+		bitemporal.Entity
+	}
+	if _, err = bitemporal.TableOf[untagged](); err == nil {
+		t.Error("Expected an error for an entity without a table name")
+	}
+}
+
+func TestRepository(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	temporalDB, err := bitemporal.NewTemporalDB(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := bitemporal.NewRepository[model.Salary](temporalDB)
+
+	ctx := bitemporal.WithValidTime(context.Background(), bitemporal.AsTime("1996-01-01"))
+	salary, err := repo.Get(ctx, 10009)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if salary.Salary != 80944 {
+		t.Errorf("Expected salary 80944 on 1996-01-01, got %d", salary.Salary)
+	}
+
+	saveCtx := bitemporal.WithTxnMoment(context.Background(), bitemporal.AsTime("2025-09-01 12:00:00"))
+	saved, err := repo.Save(saveCtx, model.Salary{EmpNo: 10009, Salary: 42}, bitemporal.AsTime("1995-01-01"), bitemporal.AsTime("2000-01-01"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 8 {
+		t.Errorf("Expected 8 records written, got %d", len(saved))
+	}
+
+	salary, err = repo.Get(bitemporal.WithSystemMoment(ctx, bitemporal.AsTime("2025-09-02")), 10009)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if salary.Salary != 42 {
+		t.Errorf("Expected salary 42 on 1996-01-01 after the save, got %d", salary.Salary)
+	}
+
+	deleteCtx := bitemporal.WithTxnMoment(context.Background(), bitemporal.AsTime("2025-09-03 12:00:00"))
+	kept, err := repo.Delete(deleteCtx, bitemporal.AsTime("1996-01-01"), bitemporal.AsTime("1997-01-01"), 10009)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kept) != 2 {
+		t.Errorf("Expected the records either side of the deleted window to be kept, got %d", len(kept))
+	}
+
+	current, err := repo.List(bitemporal.WithSystemMoment(context.Background(), bitemporal.AsTime("2025-09-04")), 10009)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range current {
+		if s.ValidOpen.Before(bitemporal.AsTime("1997-01-01")) && s.ValidClose.After(bitemporal.AsTime("1996-01-01")) {
+			t.Errorf("Expected nothing valid within the deleted window, got %s %s", s, s.Entity)
+		}
+	}
+
+	history, err := repo.History(context.Background(), 10009)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 18+len(saved)+len(kept) {
+		t.Errorf("Expected history to keep every record written, got %d", len(history))
+	}
+}
+
+func TestValidRange(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	temporalDB, err := bitemporal.NewTemporalDB(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := model.NewSalaryRepository(temporalDB)
+
+	ctx := bitemporal.WithValidRange(context.Background(), bitemporal.AsTime("1995-01-01"), bitemporal.AsTime("1997-01-01"))
+	salaries, err := repo.ForEmployee(ctx, 10009)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []int64{78335, 80944, 82507}
+	if len(salaries) != len(expected) {
+		t.Fatalf("Expected %d salaries overlapping the range, got %d", len(expected), len(salaries))
+	}
+	for i := range expected {
+		if salaries[i].Salary != expected[i] {
+			t.Errorf("Expected salary %d, got %d", expected[i], salaries[i].Salary)
+		}
+	}
+
+	// a range ending exactly where a version starts does not include it
+	ctx = bitemporal.WithValidRange(context.Background(), bitemporal.AsTime("1995-01-01"), bitemporal.AsTime("1995-02-16"))
+	salaries, err = repo.ForEmployee(ctx, 10009)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(salaries) != 1 {
+		t.Errorf("Expected 1 salary overlapping the range, got %d", len(salaries))
+	}
+
+	// a valid moment replaces the range
+	ctx = bitemporal.WithValidTime(ctx, bitemporal.AsTime("1996-01-01"))
+	if _, _, ok := bitemporal.GetValidRange(ctx); ok {
+		t.Error("Expected WithValidTime to clear the valid range")
+	}
+	salaries, err = repo.ForEmployee(ctx, 10009)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(salaries) != 1 || salaries[0].Salary != 80944 {
+		t.Errorf("Expected only the salary valid on 1996-01-01, got %v", salaries)
+	}
+}
+
+func TestSaveIfVersion(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	temporalDB, err := bitemporal.NewTemporalDB(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := model.NewSalaryRepository(temporalDB)
+
+	version, err := repo.Version(context.Background(), int64(10009))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !version.Equal(bitemporal.AsTime("2025-08-23 08:55:49.371425-07:00")) {
+		t.Errorf("Expected the version to be when the test data was recorded, got %s", version)
+	}
+
+	ctx := bitemporal.WithTxnMoment(context.Background(), testTxnMoment)
+	salary := model.Salary{EmpNo: 10009, Salary: 42}
+	if _, err = repo.SaveIfVersion(ctx, salary, bitemporal.AsTime("1995-01-01"), bitemporal.EndOfTime, version); err != nil {
+		t.Fatal(err)
+	}
+
+	// a second writer still holding the old version is turned away
+	ctx = bitemporal.WithTxnMoment(context.Background(), testTxnMoment.Add(time.Hour))
+	_, err = repo.SaveIfVersion(ctx, salary, bitemporal.AsTime("1996-01-01"), bitemporal.EndOfTime, version)
+	if !errors.Is(err, bitemporal.ErrVersionConflict) {
+		t.Errorf("Expected a version conflict, got %v", err)
+	}
+
+	version, err = repo.Version(context.Background(), int64(10009))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !version.Equal(testTxnMoment) {
+		t.Errorf("Expected the version to be the moment of the last write, got %s", version)
+	}
+}
+
+func TestSaveIfVersionSameSecond(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	temporalDB, err := bitemporal.NewTemporalDB(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := model.NewSalaryRepository(temporalDB)
+
+	ctx := bitemporal.WithTxnMoment(context.Background(), testTxnMoment)
+	salary := model.Salary{EmpNo: 10009, Salary: 42}
+	if _, err = repo.Save(ctx, salary, bitemporal.AsTime("1995-01-01"), bitemporal.EndOfTime); err != nil {
+		t.Fatal(err)
+	}
+	version, err := repo.Version(context.Background(), int64(10009))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// another write within the same second makes the version stale
+	ctx = bitemporal.WithTxnMoment(context.Background(), testTxnMoment.Add(250*time.Millisecond))
+	if _, err = repo.Save(ctx, model.Salary{EmpNo: 10009, Salary: 43}, bitemporal.AsTime("1996-01-01"), bitemporal.EndOfTime); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx = bitemporal.WithTxnMoment(context.Background(), testTxnMoment.Add(500*time.Millisecond))
+	_, err = repo.SaveIfVersion(ctx, salary, bitemporal.AsTime("1997-01-01"), bitemporal.EndOfTime, version)
+	if !errors.Is(err, bitemporal.ErrVersionConflict) {
+		t.Errorf("Expected a version conflict for a write within the same second, got %v", err)
+	}
+
+	version, err = repo.Version(context.Background(), int64(10009))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !version.Equal(testTxnMoment.Add(250 * time.Millisecond)) {
+		t.Errorf("Expected the version at full precision, got %s", version)
+	}
+}
+
+func TestSaveIfVersionConcurrently(t *testing.T) {
+	db, err := bitemporal.OpenSQLite(filepath.Join(t.TempDir(), "bitemporal.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err = db.Exec(schema); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.Exec(tempDataQuery); err != nil {
+		t.Fatal(err)
+	}
+	temporalDB, err := bitemporal.NewTemporalDB(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = temporalDB.MigratePeriods(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	repo := model.NewSalaryRepository(temporalDB)
+
+	for round := range 10 {
+		version, err := repo.Version(context.Background(), int64(10009))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// both writers hold the same version, exactly one of them may write
+		start := make(chan struct{})
+		errs := make(chan error, 2)
+		for writer := range 2 {
+			go func() {
+				<-start
+				ctx := bitemporal.WithTxnMoment(context.Background(), testTxnMoment.Add(time.Duration(2*round+writer)*time.Second))
+				_, err := repo.SaveIfVersion(ctx, model.Salary{EmpNo: 10009, Salary: int64(round*10 + writer)},
+					bitemporal.AsTime("1995-01-01"), bitemporal.EndOfTime, version)
+				errs <- err
+			}()
+		}
+		close(start)
+
+		var written, conflicts int
+		for range 2 {
+			switch err := <-errs; {
+			case err == nil:
+				written++
+			case errors.Is(err, bitemporal.ErrVersionConflict):
+				conflicts++
+			default:
+				t.Fatalf("Expected a concurrent writer to get a version conflict, got %v", err)
+			}
+		}
+		if written != 1 || conflicts != 1 {
+			t.Fatalf("Expected one write and one conflict, got %d writes and %d conflicts", written, conflicts)
+		}
+	}
+}
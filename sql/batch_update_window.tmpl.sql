@@ -0,0 +1,77 @@
+-- Same segments as update_window.tmpl.sql, computed for every key in {{ .KeysTable }} at once
+-- Before segment: only create if there's actual time before updateStart
+SELECT
+    {{ .ColumnsOf "t" }},
+    t.valid_open         valid_open,
+    @valid_open          valid_close,
+    @txn_moment          txn_open,
+    {{ .Dialect.Infinity }} txn_close
+FROM {{.Table }} t
+JOIN {{ .KeysTable }} k ON {{ .JoinString }}
+WHERE t.valid_close > @valid_open
+  AND t.valid_open < @valid_open  -- Ensure non-zero duration
+  AND t.txn_open <= @txn_moment
+  AND t.txn_close > @txn_moment
+UNION ALL
+-- Update segment: the new values, or the values computed from the superseded record, for the update window
+SELECT
+    {{ .UpdateColumnsString }},
+    CASE WHEN t.valid_open  <   @valid_open  THEN @valid_open  ELSE t.valid_open  END valid_open,
+    CASE WHEN t.valid_close >=  @valid_close THEN @valid_close ELSE t.valid_close END valid_close,
+    @txn_moment          txn_open,
+    {{ .Dialect.Infinity }} txn_close
+FROM {{.Table }} t
+JOIN {{ .KeysTable }} k ON {{ .JoinString }}
+WHERE t.valid_open < @valid_close
+  AND t.valid_close > @valid_open
+  AND t.txn_open <= @txn_moment
+  AND t.txn_close > @txn_moment
+UNION ALL
+-- After segment: preserve portions of records that start before updateEnd and extend beyond it
+SELECT
+    {{ .ColumnsOf "t" }},
+    @valid_close         valid_open,
+    t.valid_close        valid_close,
+    @txn_moment          txn_open,
+    {{ .Dialect.Infinity }} txn_close
+FROM {{.Table }} t
+JOIN {{ .KeysTable }} k ON {{ .JoinString }}
+WHERE t.valid_open < @valid_close
+  AND t.valid_close > @valid_close
+  AND t.txn_open <= @txn_moment
+  AND t.txn_close > @txn_moment
+{{- if not .Expressions }}
+UNION ALL
+-- New period segment: keys with no current record overlapping the update window
+SELECT
+    {{ .NewColumnsString }},
+    @valid_open          valid_open,
+    @valid_close         valid_close,
+    @txn_moment          txn_open,
+    {{ .Dialect.Infinity }} txn_close
+FROM {{ .KeysTable }} k
+WHERE NOT EXISTS (
+    SELECT 1 FROM {{.Table }} t
+    WHERE {{ .JoinString }}
+      AND t.valid_open < @valid_close
+      AND t.valid_close > @valid_open
+      AND t.txn_open <= @txn_moment
+      AND t.txn_close > @txn_moment
+)
+UNION ALL
+-- Extension segment: the portion of the update window before the earliest record of each key
+SELECT
+    {{ .NewColumnsString }},
+    @valid_open          valid_open,
+    MIN(t.valid_open)    valid_close,
+    @txn_moment          txn_open,
+    {{ .Dialect.Infinity }} txn_close
+FROM {{ .KeysTable }} k
+JOIN {{.Table }} t ON {{ .JoinString }}
+WHERE t.txn_open <= @txn_moment
+  AND t.txn_close > @txn_moment
+GROUP BY {{ .KeysOf "k" }}
+HAVING @valid_open < MIN(t.valid_open)
+   AND MIN(t.valid_open) < @valid_close
+{{- end }}
+ORDER BY {{ .KeyColumnsString }}, valid_open
@@ -0,0 +1,8 @@
+-- Close the transaction period of every current record of the keys in {{ .KeysTable }} overlapping the update window
+UPDATE {{.Table }} AS t
+SET txn_close = @txn_moment
+WHERE EXISTS (SELECT 1 FROM {{ .KeysTable }} k WHERE {{ .JoinString }})
+  AND t.valid_open < @valid_close
+  AND t.valid_close > @valid_open
+  AND t.txn_open <= @txn_moment
+  AND t.txn_close > @txn_moment
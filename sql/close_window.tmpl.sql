@@ -0,0 +1,14 @@
+-- Close the transaction period of every current record overlapping the update window, or touching it with the new
+-- values when coalescing
+UPDATE {{.Table }}
+SET txn_close = @txn_moment
+WHERE {{ .FiltersString }}
+{{- if .Coalesce }}
+  AND (valid_open < @valid_close OR valid_open = @valid_close AND {{ .SameValuesString }})
+  AND (valid_close > @valid_open OR valid_close = @valid_open AND {{ .SameValuesString }})
+{{- else }}
+  AND valid_open < @valid_close
+  AND valid_close > @valid_open
+{{- end }}
+  AND txn_open <= @txn_moment
+  AND txn_close > @txn_moment
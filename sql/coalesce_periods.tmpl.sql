@@ -0,0 +1,22 @@
+-- Merge adjacent periods holding the same values, repeats separated by other values or gaps are kept apart
+WITH periods AS (
+{{ .Periods }}
+), starts AS (
+    SELECT *,
+           CASE WHEN LAG(valid_close) OVER (PARTITION BY {{ .ColumnsString }} ORDER BY valid_open) = valid_open
+                THEN 0 ELSE 1 END island_start
+    FROM periods
+), islands AS (
+    SELECT *,
+           SUM(island_start) OVER (PARTITION BY {{ .ColumnsString }} ORDER BY valid_open ROWS UNBOUNDED PRECEDING) island
+    FROM starts
+)
+SELECT
+    {{ .ColumnsString }},
+    MIN(valid_open)     valid_open,
+    MAX(valid_close)    valid_close,
+    txn_open,
+    txn_close
+FROM islands
+GROUP BY {{ .ColumnsString }}, island, txn_open, txn_close
+ORDER BY valid_open
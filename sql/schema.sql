@@ -10,9 +10,9 @@ CREATE TABLE IF NOT EXISTS employees
     hire_date        DATETIME NOT NULL,
     -- Bitemporal fields
     valid_open       DATETIME NOT NULL,
-    valid_close         DATETIME NOT NULL DEFAULT '9999-12-31 23:59:59',
-    txn_open DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    txn_close   DATETIME NOT NULL DEFAULT '9999-12-31 23:59:59'
+    valid_close         DATETIME NOT NULL DEFAULT '9999-12-31T23:59:59.000000Z',
+    txn_open DATETIME NOT NULL DEFAULT (STRFTIME('%Y-%m-%dT%H:%M:%f000Z')),
+    txn_close   DATETIME NOT NULL DEFAULT '9999-12-31T23:59:59.000000Z'
 );
 -- Indexes for bitemporal queries
 CREATE INDEX IF NOT EXISTS idx_employees_bitemporal ON employees (emp_no, valid_open, valid_close);
@@ -26,9 +26,9 @@ CREATE TABLE IF NOT EXISTS departments
     dept_name        TEXT     NOT NULL,
     -- Bitemporal fields
     valid_open       DATETIME NOT NULL,
-    valid_close         DATETIME NOT NULL DEFAULT '9999-12-31 23:59:59',
-    txn_open DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    txn_close   DATETIME NOT NULL DEFAULT '9999-12-31 23:59:59'
+    valid_close         DATETIME NOT NULL DEFAULT '9999-12-31T23:59:59.000000Z',
+    txn_open DATETIME NOT NULL DEFAULT (STRFTIME('%Y-%m-%dT%H:%M:%f000Z')),
+    txn_close   DATETIME NOT NULL DEFAULT '9999-12-31T23:59:59.000000Z'
 );
 -- Indexes for bitemporal queries
 CREATE INDEX IF NOT EXISTS idx_departments_bitemporal ON departments (dept_no, valid_open, valid_close);
@@ -42,9 +42,9 @@ CREATE TABLE IF NOT EXISTS dept_manager
     dept_no          TEXT     NOT NULL,
     -- Bitemporal fields
     valid_open       DATETIME NOT NULL,
-    valid_close         DATETIME NOT NULL DEFAULT '9999-12-31 23:59:59',
-    txn_open DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    txn_close   DATETIME NOT NULL DEFAULT '9999-12-31 23:59:59'
+    valid_close         DATETIME NOT NULL DEFAULT '9999-12-31T23:59:59.000000Z',
+    txn_open DATETIME NOT NULL DEFAULT (STRFTIME('%Y-%m-%dT%H:%M:%f000Z')),
+    txn_close   DATETIME NOT NULL DEFAULT '9999-12-31T23:59:59.000000Z'
 );
 -- Indexes for bitemporal queries
 CREATE INDEX IF NOT EXISTS idx_dept_manager_bitemporal ON dept_manager (emp_no, dept_no, valid_open, valid_close);
@@ -58,9 +58,9 @@ CREATE TABLE IF NOT EXISTS dept_emp
     dept_no          TEXT     NOT NULL,
     -- Bitemporal fields
     valid_open       DATETIME NOT NULL,
-    valid_close         DATETIME NOT NULL DEFAULT '9999-12-31 23:59:59',
-    txn_open DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    txn_close   DATETIME NOT NULL DEFAULT '9999-12-31 23:59:59'
+    valid_close         DATETIME NOT NULL DEFAULT '9999-12-31T23:59:59.000000Z',
+    txn_open DATETIME NOT NULL DEFAULT (STRFTIME('%Y-%m-%dT%H:%M:%f000Z')),
+    txn_close   DATETIME NOT NULL DEFAULT '9999-12-31T23:59:59.000000Z'
 );
 -- Indexes for bitemporal queries
 CREATE INDEX IF NOT EXISTS idx_dept_emp_bitemporal ON dept_emp (emp_no, dept_no, valid_open, valid_close);
@@ -74,9 +74,9 @@ CREATE TABLE IF NOT EXISTS titles
     title            TEXT     NOT NULL,
     -- Bitemporal fields
     valid_open       DATETIME NOT NULL,
-    valid_close         DATETIME NOT NULL DEFAULT '9999-12-31 23:59:59',
-    txn_open DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    txn_close   DATETIME NOT NULL DEFAULT '9999-12-31 23:59:59'
+    valid_close         DATETIME NOT NULL DEFAULT '9999-12-31T23:59:59.000000Z',
+    txn_open DATETIME NOT NULL DEFAULT (STRFTIME('%Y-%m-%dT%H:%M:%f000Z')),
+    txn_close   DATETIME NOT NULL DEFAULT '9999-12-31T23:59:59.000000Z'
 );
 -- Indexes for bitemporal queries
 CREATE INDEX IF NOT EXISTS idx_titles_bitemporal ON titles (emp_no, valid_open, valid_close);
@@ -90,9 +90,9 @@ CREATE TABLE IF NOT EXISTS salaries
     salary           INTEGER  NOT NULL,
     -- Bitemporal fields
     valid_open       DATETIME NOT NULL,
-    valid_close         DATETIME NOT NULL DEFAULT '9999-12-31 23:59:59',
-    txn_open DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    txn_close   DATETIME NOT NULL DEFAULT '9999-12-31 23:59:59'
+    valid_close         DATETIME NOT NULL DEFAULT '9999-12-31T23:59:59.000000Z',
+    txn_open DATETIME NOT NULL DEFAULT (STRFTIME('%Y-%m-%dT%H:%M:%f000Z')),
+    txn_close   DATETIME NOT NULL DEFAULT '9999-12-31T23:59:59.000000Z'
 );
 -- Indexes for bitemporal queries
 CREATE INDEX IF NOT EXISTS idx_salaries_bitemporal ON salaries (emp_no, valid_open, valid_close);
@@ -1,91 +1,105 @@
 -- Before segment: only create if there's actual time before updateStart
 SELECT
     {{.ColumnsString }},
-    DATETIME (valid_open)               valid_open,
-    DATETIME (@valid_open)              valid_close,
-    DATETIME (current_timestamp)        txn_open,
-    DATETIME ('9999-12-31 23:59:59')    txn_close
+    valid_open           valid_open,
+    @valid_open          valid_close,
+    @txn_moment          txn_open,
+    {{ .Dialect.Infinity }} txn_close
 FROM {{.Table }}
 WHERE {{ .FiltersString }}
-  AND DATETIME(valid_close) > DATETIME(@valid_open)
-  AND DATETIME(valid_open) < DATETIME(@valid_open)  -- Ensure non-zero duration
-  AND DATETIME(txn_open) <= current_timestamp
-  AND DATETIME(txn_close) >= current_timestamp
+{{- if .Coalesce }}
+  AND (valid_close > @valid_open OR valid_close = @valid_open AND {{ .SameValuesString }}) -- Neighbours holding the new values are only rewritten when coalescing
+{{- else }}
+  AND valid_close > @valid_open -- Neighbours are only rewritten when coalescing
+{{- end }}
+  AND valid_open < @valid_open  -- Ensure non-zero duration
+  AND txn_open <= @txn_moment
+  AND txn_close > @txn_moment
+{{- if not .Delete }}
 UNION ALL
 -- Update segment: the new values for the update window
 SELECT
     {{.ColumnParamsString }},
-    DATETIME (CASE WHEN DATETIME(valid_open)    <   DATETIME(@valid_open)   THEN DATETIME(@valid_open)  ELSE DATETIME(valid_open)   END) valid_open,
-    DATETIME (CASE WHEN DATETIME(valid_close)      >=  DATETIME(@valid_close)     THEN DATETIME(@valid_close)    ELSE DATETIME(valid_close)     END) valid_close,
-    DATETIME (current_timestamp)        txn_open,
-    DATETIME ('9999-12-31 23:59:59')    txn_close
+    CASE WHEN valid_open  <  @valid_open  THEN @valid_open  ELSE valid_open  END valid_open,
+    CASE WHEN valid_close >= @valid_close THEN @valid_close ELSE valid_close END valid_close,
+    @txn_moment          txn_open,
+    {{ .Dialect.Infinity }} txn_close
     FROM {{.Table }}
     WHERE {{ .FiltersString }}
-    AND DATETIME(valid_open) < @valid_close
-    AND DATETIME(valid_close) > @valid_open
-    AND DATETIME(txn_open) <= current_timestamp
-    AND DATETIME(txn_close) >= current_timestamp
+    AND valid_open < @valid_close
+    AND valid_close > @valid_open
+    AND txn_open <= @txn_moment
+    AND txn_close > @txn_moment
 -- Ensure the calculated period has positive duration
-    AND   DATETIME(CASE WHEN DATETIME(valid_open)   <   DATETIME(@valid_open)   THEN @valid_open    ELSE valid_open END)
-        < DATETIME(CASE WHEN DATETIME(valid_close)     >=  DATETIME(@valid_close)     THEN @valid_close      ELSE valid_close   END)
+    AND   CASE WHEN valid_open  <  @valid_open  THEN @valid_open  ELSE valid_open  END
+        < CASE WHEN valid_close >= @valid_close THEN @valid_close ELSE valid_close END
+{{- end }}
 UNION ALL
 -- After segment: preserve portions of records that extend beyond updateEnd
 -- Only for records that START BEFORE updateEnd but extend beyond it
 SELECT
     {{.ColumnsString }},
-    DATETIME (@valid_close)                valid_open,
-    DATETIME (valid_close)                 valid_close,
-    DATETIME (current_timestamp)        txn_open,
-    DATETIME ('9999-12-31 23:59:59')    txn_close
+    @valid_close         valid_open,
+    valid_close          valid_close,
+    @txn_moment          txn_open,
+    {{ .Dialect.Infinity }} txn_close
 FROM {{.Table }}
 WHERE {{ .FiltersString }}
-AND DATETIME(valid_open) < DATETIME(@valid_close)          -- Must start BEFORE updateEnd
-AND DATETIME(valid_close) > DATETIME(@valid_close)            -- Must end AFTER updateEnd
-AND DATETIME(@valid_close) < DATETIME(valid_close) -- Ensure positive duration
+{{- if .Coalesce }}
+AND (valid_open < @valid_close OR valid_open = @valid_close AND {{ .SameValuesString }}) -- Must start BEFORE updateEnd, or AT it when coalescing with the new values
+{{- else }}
+AND valid_open < @valid_close          -- Must start BEFORE updateEnd, or AT it when coalescing
+{{- end }}
+AND valid_close > @valid_close            -- Must end AFTER updateEnd
+AND @valid_close < valid_close -- Ensure positive duration
+{{- if not .Coalesce }}
 -- Explicit exclusion: do not include records that start exactly at updateEnd
-AND DATETIME(valid_open) != DATETIME(@valid_close)
-AND DATETIME(txn_open) <= current_timestamp
-AND DATETIME(txn_close) >= current_timestamp
+AND valid_open != @valid_close
+{{- end }}
+AND txn_open <= @txn_moment
+AND txn_close > @txn_moment
+{{- if not .Delete }}
 UNION ALL
 -- New period segment: create new record when update window has no overlap with existing data
 -- This handles cases where the update is entirely before or after existing data
 SELECT
     {{.ColumnParamsString }},
-    DATETIME (@valid_open)              valid_open,
-    DATETIME (@valid_close)                valid_close,
-    DATETIME (current_timestamp)        txn_open,
-    DATETIME ('9999-12-31 23:59:59')    txn_close
+    @valid_open          valid_open,
+    @valid_close         valid_close,
+    @txn_moment          txn_open,
+    {{ .Dialect.Infinity }} txn_close
 WHERE NOT EXISTS (
 SELECT 1 FROM {{.Table }}
 WHERE {{ .FiltersString }}
-    AND DATETIME(valid_open) < DATETIME(@valid_close)
-    AND DATETIME(valid_close) > DATETIME(@valid_open)
-    AND DATETIME(txn_open) <= current_timestamp
-    AND DATETIME(txn_close) >= current_timestamp
+    AND valid_open < @valid_close
+    AND valid_close > @valid_open
+    AND txn_open <= @txn_moment
+    AND txn_close > @txn_moment
 )
 UNION ALL
 -- Extension segment: create record for portion of update window before the earliest existing data
 SELECT
     {{.ColumnParamsString }},
-    DATETIME (@valid_open) valid_open,
+    @valid_open          valid_open,
     (
-        SELECT DATETIME(MIN(valid_open)) valid_open
+        SELECT MIN(valid_open) valid_open
         FROM {{.Table }}
-        WHERE {{ .FiltersString }} AND DATETIME(txn_open) <= current_timestamp AND DATETIME(txn_close) >= current_timestamp
+        WHERE {{ .FiltersString }} AND txn_open <= @txn_moment AND txn_close > @txn_moment
     ) as valid_close,
-    DATETIME (current_timestamp)        txn_open,
-    DATETIME ('9999-12-31 23:59:59')    txn_close
-WHERE DATETIME(@valid_open) < (
+    @txn_moment          txn_open,
+    {{ .Dialect.Infinity }} txn_close
+WHERE @valid_open < (
     SELECT MIN(valid_open) FROM {{.Table }}
     WHERE {{ .FiltersString }}
-    AND DATETIME(txn_open) <= current_timestamp
-    AND DATETIME(txn_close) >= current_timestamp
+    AND txn_open <= @txn_moment
+    AND txn_close > @txn_moment
 ) AND EXISTS (
     SELECT 1 FROM {{.Table }}
     WHERE {{ .FiltersString }}
-        AND DATETIME(valid_open) < DATETIME(@valid_close)
-        AND DATETIME(valid_close) > DATETIME(@valid_open)
-        AND DATETIME(txn_open) <= DATETIME(current_timestamp)
-        AND DATETIME(txn_close) >= DATETIME(current_timestamp)
+        AND valid_open < @valid_close
+        AND valid_close > @valid_open
+        AND txn_open <= @txn_moment
+        AND txn_close > @txn_moment
 )
+{{- end }}
 ORDER BY valid_open
\ No newline at end of file
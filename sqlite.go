@@ -0,0 +1,174 @@
+package bitemporal
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+// Durability trades the safety of committed writes for speed, see WithDurability
+type Durability int
+
+const (
+	// Safe journals to a write-ahead log synced on every commit, readers and other processes are not blocked and
+	// committed writes survive a crash
+	Safe Durability = iota
+	// Fast keeps the journal in memory, never syncs and holds the database exclusively, so OpenSQLite limits the
+	// pool to one connection. It is meant for bulk loads like cmd/import, a crash may corrupt the database.
+	Fast
+)
+
+// defaultBusyTimeout is how long a Safe connection waits on a locked database
+const defaultBusyTimeout = 5 * time.Second
+
+func (d Durability) pragmas() []string {
+	switch d {
+	case Fast:
+		return []string{
+			"journal_mode = MEMORY",
+			"synchronous = OFF",
+			"cache_size = 100000",
+			"temp_store = MEMORY",
+			"locking_mode = EXCLUSIVE",
+		}
+	default:
+		return []string{
+			"journal_mode = WAL",
+			"synchronous = FULL",
+		}
+	}
+}
+
+// sqliteConfig is the connection setup of OpenSQLite
+type sqliteConfig struct {
+	durability  Durability
+	busyTimeout time.Duration
+	pragmas     []string
+}
+
+// SQLiteOption configures the connections OpenSQLite opens
+type SQLiteOption func(*sqliteConfig)
+
+// WithDurability sets the journal and sync pragmas of the connections, it defaults to Safe
+func WithDurability(durability Durability) SQLiteOption {
+	return func(config *sqliteConfig) {
+		config.durability = durability
+	}
+}
+
+// WithBusyTimeout sets how long a connection waits on a database locked by another connection before failing, it
+// defaults to 5 seconds for Safe
+func WithBusyTimeout(timeout time.Duration) SQLiteOption {
+	return func(config *sqliteConfig) {
+		config.busyTimeout = timeout
+	}
+}
+
+// WithPragmas adds pragmas run on every connection after those of the Durability, e.g. "cache_size = -64000"
+func WithPragmas(pragmas ...string) SQLiteOption {
+	return func(config *sqliteConfig) {
+		config.pragmas = append(config.pragmas, pragmas...)
+	}
+}
+
+// statements lists the statements setting up a connection
+func (config sqliteConfig) statements() []string {
+	pragmas := config.durability.pragmas()
+	busyTimeout := config.busyTimeout
+	if busyTimeout == 0 && config.durability == Safe {
+		busyTimeout = defaultBusyTimeout
+	}
+	if busyTimeout > 0 {
+		pragmas = append(pragmas, fmt.Sprintf("busy_timeout = %d", busyTimeout.Milliseconds()))
+	}
+	pragmas = append(pragmas, config.pragmas...)
+
+	statements := make([]string, len(pragmas))
+	for i, pragma := range pragmas {
+		statements[i] = "PRAGMA " + pragma
+	}
+	return statements
+}
+
+// OpenSQLite opens the SQLite database at dsn with SQLiteDriver, every connection the pool opens is set up with
+// the pragmas of the options. Hand the database to NewTemporalDB.
+func OpenSQLite(dsn string, opts ...SQLiteOption) (*sql.DB, error) {
+	var config sqliteConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	db, err := sql.Open(SQLiteDriver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	drv := db.Driver()
+	if err = db.Close(); err != nil {
+		return nil, err
+	}
+
+	var connector driver.Connector = dsnConnector{dsn: dsn, driver: drv}
+	if dc, ok := drv.(driver.DriverContext); ok {
+		if connector, err = dc.OpenConnector(dsn); err != nil {
+			return nil, err
+		}
+	}
+	db = sql.OpenDB(setupConnector{Connector: connector, statements: config.statements()})
+	if config.durability == Fast {
+		// a second connection would find the database locked by the first for good
+		db.SetMaxOpenConns(1)
+	}
+	return db, nil
+}
+
+// dsnConnector opens connections of a driver without a driver.Connector of its own
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// setupConnector runs statements on every connection before the pool hands it out
+type setupConnector struct {
+	driver.Connector
+	statements []string
+}
+
+func (c setupConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, statement := range c.statements {
+		if err = execConn(ctx, conn, statement); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s: %w", statement, err)
+		}
+	}
+	return conn, nil
+}
+
+func execConn(ctx context.Context, conn driver.Conn, statement string) error {
+	if execer, ok := conn.(driver.ExecerContext); ok {
+		_, err := execer.ExecContext(ctx, statement, nil)
+		if err != driver.ErrSkip {
+			return err
+		}
+	}
+	stmt, err := conn.Prepare(statement)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	_, err = stmt.Exec(nil)
+	return err
+}
@@ -0,0 +1,19 @@
+//go:build cgo && !purego
+
+package bitemporal
+
+import (
+	"errors"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// SQLiteDriver is the database/sql driver name of SQLite: github.com/mattn/go-sqlite3, or the pure Go
+// modernc.org/sqlite when built without cgo or with the purego tag
+const SQLiteDriver = "sqlite3"
+
+// isBusy reports whether SQLite failed err because another connection holds the lock it needs
+func isBusy(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code == sqlite3.ErrBusy
+}
@@ -0,0 +1,118 @@
+//go:build !cgo || purego
+
+package bitemporal
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// SQLiteDriver is the database/sql driver name of SQLite: github.com/mattn/go-sqlite3, or the pure Go
+// modernc.org/sqlite when built without cgo or with the purego tag
+const SQLiteDriver = "bitemporal_sqlite"
+
+func init() {
+	sql.Register(SQLiteDriver, puregoDriver{})
+}
+
+// isBusy reports whether SQLite failed err because another connection holds the lock it needs
+func isBusy(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code()&0xff == sqlite3.SQLITE_BUSY
+}
+
+// puregoDriver makes modernc.org/sqlite behave like github.com/mattn/go-sqlite3: times are written in the same
+// format, which DATETIME() understands, and @name parameters without an argument are bound as NULL instead of
+// failing the statement
+type puregoDriver struct{}
+
+func (puregoDriver) Open(dsn string) (driver.Conn, error) {
+	if !strings.Contains(dsn, "_time_format=") {
+		separator := "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+		dsn += separator + "_time_format=sqlite"
+	}
+	conn, err := (&sqlite.Driver{}).Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return puregoConn{conn.(sqliteConn)}, nil
+}
+
+// sqliteConn is the part of the modernc.org/sqlite connection puregoConn passes on
+type sqliteConn interface {
+	driver.Conn
+	driver.ConnBeginTx
+	driver.ConnPrepareContext
+	driver.ExecerContext
+	driver.QueryerContext
+	driver.Pinger
+	driver.SessionResetter
+	driver.Validator
+}
+
+type puregoConn struct {
+	sqliteConn
+}
+
+func (c puregoConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c puregoConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	stmt, err := c.sqliteConn.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return puregoStmt{stmt.(sqliteStmt), query}, nil
+}
+
+func (c puregoConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.sqliteConn.ExecContext(ctx, query, bindMissing(query, args))
+}
+
+func (c puregoConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.sqliteConn.QueryContext(ctx, query, bindMissing(query, args))
+}
+
+// sqliteStmt is the part of the modernc.org/sqlite statement puregoStmt passes on
+type sqliteStmt interface {
+	driver.Stmt
+	driver.StmtExecContext
+	driver.StmtQueryContext
+}
+
+type puregoStmt struct {
+	sqliteStmt
+	query string
+}
+
+func (s puregoStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.sqliteStmt.ExecContext(ctx, bindMissing(s.query, args))
+}
+
+func (s puregoStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.sqliteStmt.QueryContext(ctx, bindMissing(s.query, args))
+}
+
+// bindMissing appends a NULL argument for every @name parameter of query without one
+func bindMissing(query string, args []driver.NamedValue) []driver.NamedValue {
+	bindPositional(query, nil, false, func(name string, _ int) string {
+		for _, arg := range args {
+			if arg.Name == name {
+				return ""
+			}
+		}
+		args = append(args, driver.NamedValue{Name: name, Ordinal: len(args) + 1})
+		return ""
+	})
+	return args
+}
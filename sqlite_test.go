@@ -0,0 +1,112 @@
+package bitemporal_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pborges/bitemporal"
+)
+
+func TestOpenSQLite(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		opts    []bitemporal.SQLiteOption
+		pragmas map[string]string
+	}{
+		{
+			name:    "safe",
+			pragmas: map[string]string{"journal_mode": "wal", "synchronous": "2", "busy_timeout": "5000"},
+		},
+		{
+			name: "fast",
+			opts: []bitemporal.SQLiteOption{
+				bitemporal.WithDurability(bitemporal.Fast),
+				bitemporal.WithBusyTimeout(time.Second),
+				bitemporal.WithPragmas("cache_size = 1234"),
+			},
+			pragmas: map[string]string{"journal_mode": "memory", "synchronous": "0", "busy_timeout": "1000", "cache_size": "1234", "locking_mode": "exclusive"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, err := bitemporal.OpenSQLite(filepath.Join(t.TempDir(), "bitemporal.db"), test.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			if _, err = db.Exec(temporalSchema); err != nil {
+				t.Fatalf("Failed to execute schema: %v", err)
+			}
+			if _, err = bitemporal.NewTemporalDB(db); err != nil {
+				t.Fatalf("Failed to create TemporalDB: %v", err)
+			}
+
+			// every connection of the pool is set up, not only the first one: discard a connection whose busy
+			// timeout was changed, the next one must be opened anew
+			first, err := db.Conn(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err = first.ExecContext(ctx, "PRAGMA busy_timeout = 1"); err != nil {
+				t.Fatal(err)
+			}
+			first.Raw(func(any) error { return driver.ErrBadConn })
+			conn, err := db.Conn(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for pragma, expected := range test.pragmas {
+				var actual string
+				if err = conn.QueryRowContext(ctx, "PRAGMA "+pragma).Scan(&actual); err != nil {
+					t.Fatal(err)
+				}
+				if actual != expected {
+					t.Errorf("Expected %s = %s, got %s", pragma, expected, actual)
+				}
+			}
+			conn.Close()
+
+			// a second writer waits for the first instead of finding the database locked
+			tx, err := db.Begin()
+			if err != nil {
+				t.Fatal(err)
+			}
+			insert := "INSERT INTO titles (emp_no, title, valid_open) VALUES (?, 'Engineer', '2020-01-01T00:00:00.000000Z')"
+			if _, err = tx.Exec(insert, 1); err != nil {
+				t.Fatal(err)
+			}
+			started := make(chan struct{})
+			second := make(chan error, 1)
+			go func() {
+				close(started)
+				_, err := db.ExecContext(ctx, insert, 2)
+				second <- err
+			}()
+			<-started
+			// the second writer cannot finish while the first holds the write lock
+			select {
+			case err = <-second:
+				t.Errorf("Expected the second writer to wait for the first, it returned %v", err)
+			default:
+			}
+			if err = tx.Commit(); err != nil {
+				t.Fatal(err)
+			}
+			if err = <-second; err != nil {
+				t.Errorf("Expected the second writer to succeed, got %v", err)
+			}
+
+			var titles int
+			if err = db.QueryRow("SELECT COUNT(*) FROM titles").Scan(&titles); err != nil {
+				t.Fatal(err)
+			}
+			if titles != 2 {
+				t.Errorf("Expected both writes, got %d titles", titles)
+			}
+		})
+	}
+}
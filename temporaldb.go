@@ -4,44 +4,166 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 )
 
 const dumpQueries = false
 
-var pragmas = []string{
-	"PRAGMA journal_mode = MEMORY",
-	"PRAGMA synchronous = OFF",
-	"PRAGMA cache_size = 100000",
-	"PRAGMA temp_store = MEMORY",
-	"PRAGMA locking_mode = EXCLUSIVE",
-}
-
-var Schema []Table
-
 type Table struct {
 	Name    string
 	Columns []string
+	// Keys are the columns identifying an entity over time, they are also listed in Columns
+	Keys []string
+	// References are the tables this table depends on over valid time, see CheckReferences
+	References []Reference
+}
+
+// Clock provides the transaction moment of writes made without a TxnMoment in their TemporalContext
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to a Clock
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time {
+	return f()
 }
 
-func NewTemporalDB(database *sql.DB) (*TemporalDB, error) {
+type Option func(*TemporalDB)
+
+// WithRegistry replaces DefaultRegistry as the set of tables the TemporalDB knows about, NewTemporalDB validates
+// it against the database
+func WithRegistry(registry *Registry) Option {
+	return func(repo *TemporalDB) {
+		repo.registry = registry
+	}
+}
+
+// WithClock replaces the wall clock used for transaction moments, for replaying, back-filling and tests
+func WithClock(clock Clock) Option {
+	return func(repo *TemporalDB) {
+		repo.clock = clock
+	}
+}
+
+// WithDialect replaces SQLite as the SQL dialect of the TemporalDB
+func WithDialect(dialect Dialect) Option {
+	return func(repo *TemporalDB) {
+		repo.dialect = dialect
+	}
+}
+
+// Write describes the keys an update window changed, it is handed to every PreCommitHook
+type Write struct {
+	Table     string
+	Keys      []map[string]any
+	ValidFrom time.Time
+	ValidTo   time.Time
+	TxnMoment time.Time
+}
+
+// PreCommitHook runs inside the transaction of every write after its records are written, an error rolls it back
+type PreCommitHook func(ctx context.Context, repo *TemporalDB, tx *sql.Tx, write Write) error
+
+// WithPreCommitHook adds a hook run before every write is committed, e.g. ReferenceCheck
+func WithPreCommitHook(hook PreCommitHook) Option {
+	return func(repo *TemporalDB) {
+		repo.hooks = append(repo.hooks, hook)
+	}
+}
+
+func NewTemporalDB(database *sql.DB, opts ...Option) (*TemporalDB, error) {
 	if err := database.Ping(); err != nil {
 		return nil, err
 	}
 
-	for _, pragma := range pragmas {
-		if _, err := database.Exec(pragma); err != nil {
+	repo := &TemporalDB{
+		db:      database,
+		clock:   ClockFunc(time.Now),
+		dialect: SQLite,
+	}
+	for _, opt := range opts {
+		opt(repo)
+	}
+
+	for _, statement := range repo.dialect.Session() {
+		if _, err := database.Exec(statement); err != nil {
 			return nil, err
 		}
 	}
 
-	return &TemporalDB{database, Schema}, nil
+	if repo.registry == nil {
+		repo.temporalTables = defaultTables()
+		return repo, nil
+	}
+	if err := repo.registry.ValidateWith(context.Background(), database, repo.dialect); err != nil {
+		return nil, err
+	}
+	repo.temporalTables = repo.registry.Tables()
+	return repo, nil
 }
 
 type TemporalDB struct {
 	db             *sql.DB
+	registry       *Registry
 	temporalTables []Table
+	clock          Clock
+	hooks          []PreCommitHook
+	dialect        Dialect
+}
+
+func (repo *TemporalDB) preCommit(ctx context.Context, tx *sql.Tx, write Write) error {
+	for _, hook := range repo.hooks {
+		if err := hook(ctx, repo, tx, write); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// txnMoment is the transaction moment of a write, the TxnMoment of the TemporalContext when set. The system moment
+// is never used, it may come from the known_at of a request.
+func (repo *TemporalDB) txnMoment(ctx context.Context) time.Time {
+	if moment := GetTxnMoment(ctx); !moment.IsZero() {
+		return moment
+	}
+	return repo.clock.Now()
+}
+
+// systemMoment is the moment a read spanning several queries sees, the system moment of the TemporalContext when set
+func (repo *TemporalDB) systemMoment(ctx context.Context) time.Time {
+	if moment := GetSystemMoment(ctx); !moment.IsZero() {
+		return moment
+	}
+	return repo.clock.Now()
+}
+
+func (repo *TemporalDB) table(name string) (Table, bool) {
+	for _, table := range repo.temporalTables {
+		if table.Name == name {
+			return table, true
+		}
+	}
+	return Table{}, false
+}
+
+// keyColumns validates key against the table and returns its columns in a stable order
+func keyColumns(table Table, key map[string]any) ([]string, error) {
+	columns := make([]string, 0, len(key))
+	for column := range key {
+		if !slices.Contains(table.Columns, column) {
+			return nil, fmt.Errorf("table %q has no column %q", table.Name, column)
+		}
+		columns = append(columns, column)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("table %q requires a key", table.Name)
+	}
+	slices.Sort(columns)
+	return columns, nil
 }
 
 func (repo *TemporalDB) Close() error {
@@ -53,19 +175,51 @@ func (repo *TemporalDB) Ping() error {
 }
 
 func (repo *TemporalDB) Query(ctx context.Context, query string, args map[string]any) (*sql.Rows, error) {
-	fragment := repo.prepareQuery(ctx, QueryFragment{query, args})
-	return repo.db.Query(fragment.Query, fragment.Args()...)
+	query, bound := repo.bind(repo.prepareQuery(ctx, QueryFragment{query, args}))
+	return repo.db.QueryContext(ctx, query, bound...)
+}
+
+// derivedPredicate restricts a derived table to the TemporalContext, each condition holds while its parameters are
+// unbound, see prepareQuery
+const derivedPredicate = `(@valid_open IS NULL OR valid_open <= @valid_open AND @valid_close < valid_close)
+    AND (@valid_range_open IS NULL OR valid_open < @valid_range_close AND @valid_range_open < valid_close)
+    AND (@txn_open IS NULL OR txn_open <= @txn_open AND @txn_close < txn_close)`
+
+// View renders a reference to the temporal view of table for the FROM clause of a query, aliased by alias unless it
+// is empty. The view is named by the Dialect and declared by Query, or rendered in place as a derived table when the
+// Dialect lacks common table expressions, so portable queries reference views with View instead of naming them.
+func (repo *TemporalDB) View(table, alias string) string {
+	name := repo.dialect.ViewName(table)
+	if derived, ok := repo.dialect.(derivedTables); ok && derived.derivedViews() {
+		if alias == "" {
+			alias = name
+		}
+		return fmt.Sprintf("(SELECT * FROM %s WHERE %s) %s", table, derivedPredicate, alias)
+	}
+	if alias == "" {
+		return name
+	}
+	return name + " " + alias
 }
 
 func (repo *TemporalDB) prepareQuery(ctx context.Context, fragment QueryFragment) QueryFragment {
 	validMoment := GetValidMoment(ctx)
 	systemMoment := GetSystemMoment(ctx)
+	rangeOpen, rangeClose, hasRange := GetValidRange(ctx)
+
+	if fragment.ArgMap == nil {
+		fragment.ArgMap = make(map[string]any)
+	}
 
 	// Add temporal parameters once
 	if !validMoment.IsZero() {
 		fragment.ArgMap["valid_open"] = validMoment
 		fragment.ArgMap["valid_close"] = validMoment
 	}
+	if hasRange {
+		fragment.ArgMap["valid_range_open"] = rangeOpen
+		fragment.ArgMap["valid_range_close"] = rangeClose
+	}
 	if !systemMoment.IsZero() {
 		fragment.ArgMap["txn_open"] = systemMoment
 		fragment.ArgMap["txn_close"] = systemMoment
@@ -79,16 +233,22 @@ func (repo *TemporalDB) prepareQuery(ctx context.Context, fragment QueryFragment
 		if !validMoment.IsZero() {
 			filters = append(filters, "valid_open <= @valid_open AND @valid_close < valid_close")
 		}
+		if hasRange {
+			filters = append(filters, "valid_open < @valid_range_close AND @valid_range_open < valid_close")
+		}
 		if !systemMoment.IsZero() {
 			filters = append(filters, "txn_open <= @txn_open AND @txn_close < txn_close")
 		}
-		if !validMoment.IsZero() || !systemMoment.IsZero() {
+		if len(filters) > 0 {
 			predicate = " WHERE (" + strings.Join(filters, " AND ") + ")"
 		}
-		ctes = append(ctes, fmt.Sprintf("\n%s$ as (SELECT * FROM %s%s)", table.Name, table.Name, predicate))
+		ctes = append(ctes, fmt.Sprintf("\n%s as (SELECT * FROM %s%s)", repo.dialect.ViewName(table.Name), table.Name, predicate))
 	}
 
-	fragment.Query = fmt.Sprintf("WITH %s \n%s", strings.Join(ctes, ","), fragment.Query)
+	// derived tables carry their own predicate, see View
+	if derived, ok := repo.dialect.(derivedTables); !ok || !derived.derivedViews() {
+		fragment.Query = fmt.Sprintf("WITH %s \n%s", strings.Join(ctes, ","), fragment.Query)
+	}
 
 	if dumpQueries {
 		fmt.Printf("- QUERY [VALID: %s SYS: %s] ----------------------------\n", validMoment.Format(time.Stamp), systemMoment.Format(time.Stamp))
@@ -102,6 +262,6 @@ func (repo *TemporalDB) prepareQuery(ctx context.Context, fragment QueryFragment
 }
 
 func (repo *TemporalDB) QueryRow(ctx context.Context, query string, args map[string]any) *sql.Row {
-	fragment := repo.prepareQuery(ctx, QueryFragment{query, args})
-	return repo.db.QueryRow(fragment.Query, fragment.Args()...)
+	query, bound := repo.bind(repo.prepareQuery(ctx, QueryFragment{query, args}))
+	return repo.db.QueryRowContext(ctx, query, bound...)
 }
@@ -4,10 +4,10 @@ import (
 	"context"
 	"database/sql"
 	_ "embed"
+	"errors"
 	"testing"
 	"time"
 
-	_ "github.com/mattn/go-sqlite3"
 	"github.com/pborges/bitemporal"
 	"github.com/pborges/bitemporal/model"
 )
@@ -21,10 +21,12 @@ var temporalSchema string
 var validTimeData string
 
 func createTemporalTestDB(t *testing.T) (*bitemporal.TemporalDB, *model.EmployeeRepository, func()) {
-	db, err := sql.Open("sqlite3", ":memory:")
+	db, err := sql.Open(bitemporal.SQLiteDriver, ":memory:")
 	if err != nil {
 		t.Fatalf("Failed to open in-memory database: %v", err)
 	}
+	// every connection to :memory: is a new database
+	db.SetMaxOpenConns(1)
 
 	_, err = db.Exec(temporalSchema)
 	if err != nil {
@@ -40,6 +42,9 @@ func createTemporalTestDB(t *testing.T) (*bitemporal.TemporalDB, *model.Employee
 	if err != nil {
 		t.Fatalf("Failed to create TemporalDB: %v", err)
 	}
+	if _, err = temporalDB.MigratePeriods(context.Background()); err != nil {
+		t.Fatalf("Failed to migrate test data: %v", err)
+	}
 
 	employeeRepo := model.NewEmployeeRepository(temporalDB)
 
@@ -236,3 +241,155 @@ func TestTransactionTimeProgression(t *testing.T) {
 		})
 	}
 }
+
+func TestEmployeeSave(t *testing.T) {
+	_, repo, cleanup := createTemporalTestDB(t)
+	defer cleanup()
+
+	// Jane's surname was misspelled all along, HR fixes it from the marriage onward on 2024-01-02
+	ctx := bitemporal.WithTxnMoment(context.Background(), bitemporal.AsTime("2024-01-02 09:00:00"))
+	saved, err := repo.Save(ctx, model.Employee{
+		EmpNo:     12345,
+		BirthDate: bitemporal.AsTime("1990-03-15"),
+		FirstName: "Jane",
+		LastName:  "O'Brien",
+		Gender:    "F",
+		HireDate:  bitemporal.AsTime("2020-01-15"),
+	}, bitemporal.AsTime("2023-06-10"), bitemporal.EndOfTime)
+	if err != nil {
+		t.Fatalf("Failed to save employee: %v", err)
+	}
+
+	for _, emp := range saved {
+		if debugTemporal {
+			t.Logf("Saved: %s %s", emp, emp.Entity)
+		}
+		if !emp.TxnOpen.Equal(bitemporal.AsTime("2024-01-02 09:00:00")) {
+			t.Errorf("Expected saved rows to be recorded at the context transaction moment, got %s", emp.TxnOpen)
+		}
+		if !emp.BirthDate.Equal(bitemporal.AsTime("1990-03-15")) {
+			t.Errorf("Expected birth date to survive the save, got %s", emp.BirthDate)
+		}
+	}
+
+	employee := queryEmployeeAtTime(t, repo, 12345, bitemporal.AsTime("2023-06-12"), bitemporal.AsTime("2024-01-03"))
+	if employee.LastName != "O'Brien" {
+		t.Errorf("Expected last name 'O'Brien' after save, got '%s'", employee.LastName)
+	}
+
+	// Before the save we still believed she was Johnson
+	employee = queryEmployeeAtTime(t, repo, 12345, bitemporal.AsTime("2023-06-12"), bitemporal.AsTime("2023-12-31"))
+	if employee.LastName != "Johnson" {
+		t.Errorf("Expected last name 'Johnson' before the save was recorded, got '%s'", employee.LastName)
+	}
+
+	// The portion before the window is untouched
+	employee = queryEmployeeAtTime(t, repo, 12345, bitemporal.AsTime("2023-06-09"), bitemporal.AsTime("2024-01-03"))
+	if employee.LastName != "Smith" {
+		t.Errorf("Expected last name 'Smith' before the window, got '%s'", employee.LastName)
+	}
+}
+
+func TestTerminate(t *testing.T) {
+	temporalDB, repo, cleanup := createTemporalTestDB(t)
+	defer cleanup()
+
+	// Jane leaves the company on 2024-02-14, HR records it on 2024-03-01
+	ctx := bitemporal.WithTxnMoment(context.Background(), bitemporal.AsTime("2024-03-01 09:00:00"))
+	trimmed, err := temporalDB.Terminate(ctx, "employees", map[string]any{"emp_no": 12345}, bitemporal.AsTime("2024-02-14"))
+	if err != nil {
+		t.Fatalf("Failed to terminate employee: %v", err)
+	}
+	for _, period := range trimmed {
+		if !period.ValidClose.Equal(bitemporal.AsTime("2024-02-14")) {
+			t.Errorf("Expected trimmed records to close at the termination, got %s", period.Entity)
+		}
+	}
+
+	// Before the termination was recorded she was still employed
+	employee := queryEmployeeAtTime(t, repo, 12345, bitemporal.AsTime("2024-05-01"), bitemporal.AsTime("2024-02-20"))
+	if employee.LastName != "Johnson" {
+		t.Errorf("Expected last name 'Johnson' before the termination was recorded, got '%s'", employee.LastName)
+	}
+
+	// Afterward there is nothing valid past the termination
+	ctx = bitemporal.WithValidTime(context.Background(), bitemporal.AsTime("2024-05-01"))
+	ctx = bitemporal.WithSystemMoment(ctx, bitemporal.AsTime("2024-03-02"))
+	if _, err = repo.ById(ctx, 12345); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected no employee after the termination, got %v", err)
+	}
+
+	// But the valid time before the termination is untouched
+	employee = queryEmployeeAtTime(t, repo, 12345, bitemporal.AsTime("2023-06-12"), bitemporal.AsTime("2024-03-02"))
+	if employee.LastName != "Johnson" {
+		t.Errorf("Expected last name 'Johnson' before the termination, got '%s'", employee.LastName)
+	}
+
+	if _, err = temporalDB.Terminate(ctx, "employees", map[string]any{"nope": 1}, bitemporal.AsTime("2024-02-14")); err == nil {
+		t.Error("Expected an error terminating by an unknown column")
+	}
+}
+
+func TestHistory(t *testing.T) {
+	temporalDB, _, cleanup := createTemporalTestDB(t)
+	defer cleanup()
+
+	changes, err := temporalDB.History(context.Background(), "employees", map[string]any{"emp_no": 12345})
+	if err != nil {
+		t.Fatalf("Failed to query history: %v", err)
+	}
+
+	lastName := func(values map[string]any) string {
+		if values == nil {
+			return "-"
+		}
+		return values["last_name"].(string)
+	}
+
+	if debugTemporal {
+		for _, change := range changes {
+			t.Logf("At %s valid %s to %s: %s -> %s", change.TxnMoment.Format(time.DateTime),
+				change.ValidOpen.Format(time.DateOnly), change.ValidClose.Format(time.DateOnly),
+				lastName(change.Old), lastName(change.New))
+		}
+	}
+
+	expected := []struct {
+		txnMoment, validOpen, validClose, old, new string
+	}{
+		{"2020-01-15 09:00:00", "2020-01-15", "2023-06-15", "-", "Smith"},
+		{"2023-07-01 14:30:00", "2020-01-15", "2023-06-15", "Smith", "-"},
+		{"2023-07-01 14:30:00", "2023-06-15", "9999-12-31 23:59:59", "-", "Johnson"},
+		{"2023-08-15 10:15:00", "2020-01-15", "2023-06-10", "-", "Smith"},
+		{"2023-08-15 10:15:00", "2023-06-10", "2023-06-15", "-", "Johnson"},
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d belief changes, got %d", len(expected), len(changes))
+	}
+	for i, e := range expected {
+		change := changes[i]
+		if !change.TxnMoment.Equal(bitemporal.AsTime(e.txnMoment)) ||
+			!change.ValidOpen.Equal(bitemporal.AsTime(e.validOpen)) ||
+			!change.ValidClose.Equal(bitemporal.AsTime(e.validClose)) ||
+			lastName(change.Old) != e.old || lastName(change.New) != e.new {
+			t.Errorf("Change %d: expected %v, got %+v", i, e, change)
+		}
+	}
+
+	// Who was told Jane's name was Smith on 2023-06-12, and until when?
+	marriage := bitemporal.AsTime("2023-06-12")
+	var told, until time.Time
+	for _, change := range changes {
+		if change.ValidOpen.After(marriage) || !change.ValidClose.After(marriage) {
+			continue
+		}
+		if lastName(change.New) == "Smith" && told.IsZero() {
+			told = change.TxnMoment
+		} else if lastName(change.Old) == "Smith" && !told.IsZero() && until.IsZero() {
+			until = change.TxnMoment
+		}
+	}
+	if !told.Equal(bitemporal.AsTime("2020-01-15 09:00:00")) || !until.Equal(bitemporal.AsTime("2023-07-01 14:30:00")) {
+		t.Errorf("Expected Smith to be believed from 2020-01-15 09:00 until 2023-07-01 14:30, got %s until %s", told, until)
+	}
+}
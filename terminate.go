@@ -0,0 +1,37 @@
+package bitemporal
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Terminate ends the valid time of the entity identified by key at validFrom: every current record is trimmed to
+// close at validFrom and records starting later are removed. The trim is recorded as a new transaction so
+// as-of queries from before the termination still see the entity.
+func (repo *TemporalDB) Terminate(ctx context.Context, table string, key map[string]any, validFrom time.Time) ([]Period, error) {
+	t, ok := repo.table(table)
+	if !ok {
+		return nil, fmt.Errorf("table %q is not registered", table)
+	}
+
+	filterBy, err := keyColumns(t, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.ApplyUpdateWindow(ctx, UpdateWindow{
+		Table:     t.Name,
+		Select:    t.Columns,
+		FilterBy:  filterBy,
+		Values:    key,
+		ValidFrom: validFrom,
+		ValidTo:   EndOfTime,
+		Delete:    true,
+	})
+}
+
+// Terminate ends the valid time of the entity identified by key at validFrom, see TemporalDB.Terminate
+func (r Repository[T]) Terminate(ctx context.Context, validFrom time.Time, key ...any) ([]T, error) {
+	return r.Delete(ctx, validFrom, EndOfTime, key...)
+}
@@ -0,0 +1,13 @@
+WITH 
+`salaries$` as (SELECT * FROM salaries WHERE (valid_open <= ? AND ? < valid_close AND txn_open <= ? AND ? < txn_close)),
+`titles$` as (SELECT * FROM titles WHERE (valid_open <= ? AND ? < valid_close AND txn_open <= ? AND ? < txn_close)) 
+SELECT * FROM `salaries$` WHERE emp_no = ?
+-- 1 = time.Date(1995, time.June, 1, 0, 0, 0, 0, time.UTC)
+-- 2 = time.Date(1995, time.June, 1, 0, 0, 0, 0, time.UTC)
+-- 3 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 4 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 5 = time.Date(1995, time.June, 1, 0, 0, 0, 0, time.UTC)
+-- 6 = time.Date(1995, time.June, 1, 0, 0, 0, 0, time.UTC)
+-- 7 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 8 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 9 = 10009
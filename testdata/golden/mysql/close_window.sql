@@ -0,0 +1,15 @@
+-- Close the transaction period of every current record overlapping the update window, or touching it with the new
+-- values when coalescing
+UPDATE salaries
+SET txn_close = ?
+WHERE emp_no = ?
+  AND valid_open < ?
+  AND valid_close > ?
+  AND txn_open <= ?
+  AND txn_close > ?
+-- 1 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 2 = 10009
+-- 3 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 4 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 5 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 6 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
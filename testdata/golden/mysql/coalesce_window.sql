@@ -0,0 +1,171 @@
+-- Merge adjacent periods holding the same values, repeats separated by other values or gaps are kept apart
+WITH periods AS (
+-- Before segment: only create if there's actual time before updateStart
+SELECT
+    emp_no, salary,
+    valid_open           valid_open,
+    ?          valid_close,
+    ?          txn_open,
+    TIMESTAMP '9999-12-31 23:59:59.000000' txn_close
+FROM salaries
+WHERE emp_no = ?
+  AND (valid_close > ? OR valid_close = ? AND salary = ?) -- Neighbours holding the new values are only rewritten when coalescing
+  AND valid_open < ?  -- Ensure non-zero duration
+  AND txn_open <= ?
+  AND txn_close > ?
+UNION ALL
+-- Update segment: the new values for the update window
+SELECT
+    ? as emp_no, ? as salary,
+    CASE WHEN valid_open  <  ?  THEN ?  ELSE valid_open  END valid_open,
+    CASE WHEN valid_close >= ? THEN ? ELSE valid_close END valid_close,
+    ?          txn_open,
+    TIMESTAMP '9999-12-31 23:59:59.000000' txn_close
+    FROM salaries
+    WHERE emp_no = ?
+    AND valid_open < ?
+    AND valid_close > ?
+    AND txn_open <= ?
+    AND txn_close > ?
+-- Ensure the calculated period has positive duration
+    AND   CASE WHEN valid_open  <  ?  THEN ?  ELSE valid_open  END
+        < CASE WHEN valid_close >= ? THEN ? ELSE valid_close END
+UNION ALL
+-- After segment: preserve portions of records that extend beyond updateEnd
+-- Only for records that START BEFORE updateEnd but extend beyond it
+SELECT
+    emp_no, salary,
+    ?         valid_open,
+    valid_close          valid_close,
+    ?          txn_open,
+    TIMESTAMP '9999-12-31 23:59:59.000000' txn_close
+FROM salaries
+WHERE emp_no = ?
+AND (valid_open < ? OR valid_open = ? AND salary = ?) -- Must start BEFORE updateEnd, or AT it when coalescing with the new values
+AND valid_close > ?            -- Must end AFTER updateEnd
+AND ? < valid_close -- Ensure positive duration
+AND txn_open <= ?
+AND txn_close > ?
+UNION ALL
+-- New period segment: create new record when update window has no overlap with existing data
+-- This handles cases where the update is entirely before or after existing data
+SELECT
+    ? as emp_no, ? as salary,
+    ?          valid_open,
+    ?         valid_close,
+    ?          txn_open,
+    TIMESTAMP '9999-12-31 23:59:59.000000' txn_close
+WHERE NOT EXISTS (
+SELECT 1 FROM salaries
+WHERE emp_no = ?
+    AND valid_open < ?
+    AND valid_close > ?
+    AND txn_open <= ?
+    AND txn_close > ?
+)
+UNION ALL
+-- Extension segment: create record for portion of update window before the earliest existing data
+SELECT
+    ? as emp_no, ? as salary,
+    ?          valid_open,
+    (
+        SELECT MIN(valid_open) valid_open
+        FROM salaries
+        WHERE emp_no = ? AND txn_open <= ? AND txn_close > ?
+    ) as valid_close,
+    ?          txn_open,
+    TIMESTAMP '9999-12-31 23:59:59.000000' txn_close
+WHERE ? < (
+    SELECT MIN(valid_open) FROM salaries
+    WHERE emp_no = ?
+    AND txn_open <= ?
+    AND txn_close > ?
+) AND EXISTS (
+    SELECT 1 FROM salaries
+    WHERE emp_no = ?
+        AND valid_open < ?
+        AND valid_close > ?
+        AND txn_open <= ?
+        AND txn_close > ?
+)
+ORDER BY valid_open
+), starts AS (
+    SELECT *,
+           CASE WHEN LAG(valid_close) OVER (PARTITION BY emp_no, salary ORDER BY valid_open) = valid_open
+                THEN 0 ELSE 1 END island_start
+    FROM periods
+), islands AS (
+    SELECT *,
+           SUM(island_start) OVER (PARTITION BY emp_no, salary ORDER BY valid_open ROWS UNBOUNDED PRECEDING) island
+    FROM starts
+)
+SELECT
+    emp_no, salary,
+    MIN(valid_open)     valid_open,
+    MAX(valid_close)    valid_close,
+    txn_open,
+    txn_close
+FROM islands
+GROUP BY emp_no, salary, island, txn_open, txn_close
+ORDER BY valid_open
+-- 1 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 2 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 3 = 10009
+-- 4 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 5 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 6 = 42
+-- 7 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 8 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 9 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 10 = 10009
+-- 11 = 42
+-- 12 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 13 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 14 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 15 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 16 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 17 = 10009
+-- 18 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 19 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 20 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 21 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 22 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 23 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 24 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 25 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 26 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 27 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 28 = 10009
+-- 29 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 30 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 31 = 42
+-- 32 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 33 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 34 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 35 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 36 = 10009
+-- 37 = 42
+-- 38 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 39 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 40 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 41 = 10009
+-- 42 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 43 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 44 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 45 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 46 = 10009
+-- 47 = 42
+-- 48 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 49 = 10009
+-- 50 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 51 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 52 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 53 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 54 = 10009
+-- 55 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 56 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 57 = 10009
+-- 58 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 59 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 60 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 61 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
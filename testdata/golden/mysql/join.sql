@@ -0,0 +1,21 @@
+WITH 
+`salaries$` as (SELECT * FROM salaries WHERE (valid_open <= ? AND ? < valid_close AND txn_open <= ? AND ? < txn_close)),
+`titles$` as (SELECT * FROM titles WHERE (valid_open <= ? AND ? < valid_close AND txn_open <= ? AND ? < txn_close)) 
+SELECT l.emp_no, l.salary, r.emp_no, r.title,
+    GREATEST(l.valid_open, r.valid_open) valid_open,
+    LEAST(l.valid_close, r.valid_close) valid_close,
+    GREATEST(l.txn_open, r.txn_open) txn_open,
+    LEAST(l.txn_close, r.txn_close) txn_close
+FROM `salaries$` l
+JOIN `titles$` r ON l.emp_no = r.emp_no
+  AND l.valid_open < r.valid_close AND r.valid_open < l.valid_close
+  AND l.txn_open < r.txn_close AND r.txn_open < l.txn_close
+ORDER BY l.emp_no, valid_open, txn_open
+-- 1 = time.Date(1995, time.June, 1, 0, 0, 0, 0, time.UTC)
+-- 2 = time.Date(1995, time.June, 1, 0, 0, 0, 0, time.UTC)
+-- 3 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 4 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 5 = time.Date(1995, time.June, 1, 0, 0, 0, 0, time.UTC)
+-- 6 = time.Date(1995, time.June, 1, 0, 0, 0, 0, time.UTC)
+-- 7 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 8 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
@@ -0,0 +1,149 @@
+-- Before segment: only create if there's actual time before updateStart
+SELECT
+    emp_no, salary,
+    valid_open           valid_open,
+    ?          valid_close,
+    ?          txn_open,
+    TIMESTAMP '9999-12-31 23:59:59.000000' txn_close
+FROM salaries
+WHERE emp_no = ?
+  AND valid_close > ? -- Neighbours are only rewritten when coalescing
+  AND valid_open < ?  -- Ensure non-zero duration
+  AND txn_open <= ?
+  AND txn_close > ?
+UNION ALL
+-- Update segment: the new values for the update window
+SELECT
+    ? as emp_no, ? as salary,
+    CASE WHEN valid_open  <  ?  THEN ?  ELSE valid_open  END valid_open,
+    CASE WHEN valid_close >= ? THEN ? ELSE valid_close END valid_close,
+    ?          txn_open,
+    TIMESTAMP '9999-12-31 23:59:59.000000' txn_close
+    FROM salaries
+    WHERE emp_no = ?
+    AND valid_open < ?
+    AND valid_close > ?
+    AND txn_open <= ?
+    AND txn_close > ?
+-- Ensure the calculated period has positive duration
+    AND   CASE WHEN valid_open  <  ?  THEN ?  ELSE valid_open  END
+        < CASE WHEN valid_close >= ? THEN ? ELSE valid_close END
+UNION ALL
+-- After segment: preserve portions of records that extend beyond updateEnd
+-- Only for records that START BEFORE updateEnd but extend beyond it
+SELECT
+    emp_no, salary,
+    ?         valid_open,
+    valid_close          valid_close,
+    ?          txn_open,
+    TIMESTAMP '9999-12-31 23:59:59.000000' txn_close
+FROM salaries
+WHERE emp_no = ?
+AND valid_open < ?          -- Must start BEFORE updateEnd, or AT it when coalescing
+AND valid_close > ?            -- Must end AFTER updateEnd
+AND ? < valid_close -- Ensure positive duration
+-- Explicit exclusion: do not include records that start exactly at updateEnd
+AND valid_open != ?
+AND txn_open <= ?
+AND txn_close > ?
+UNION ALL
+-- New period segment: create new record when update window has no overlap with existing data
+-- This handles cases where the update is entirely before or after existing data
+SELECT
+    ? as emp_no, ? as salary,
+    ?          valid_open,
+    ?         valid_close,
+    ?          txn_open,
+    TIMESTAMP '9999-12-31 23:59:59.000000' txn_close
+WHERE NOT EXISTS (
+SELECT 1 FROM salaries
+WHERE emp_no = ?
+    AND valid_open < ?
+    AND valid_close > ?
+    AND txn_open <= ?
+    AND txn_close > ?
+)
+UNION ALL
+-- Extension segment: create record for portion of update window before the earliest existing data
+SELECT
+    ? as emp_no, ? as salary,
+    ?          valid_open,
+    (
+        SELECT MIN(valid_open) valid_open
+        FROM salaries
+        WHERE emp_no = ? AND txn_open <= ? AND txn_close > ?
+    ) as valid_close,
+    ?          txn_open,
+    TIMESTAMP '9999-12-31 23:59:59.000000' txn_close
+WHERE ? < (
+    SELECT MIN(valid_open) FROM salaries
+    WHERE emp_no = ?
+    AND txn_open <= ?
+    AND txn_close > ?
+) AND EXISTS (
+    SELECT 1 FROM salaries
+    WHERE emp_no = ?
+        AND valid_open < ?
+        AND valid_close > ?
+        AND txn_open <= ?
+        AND txn_close > ?
+)
+ORDER BY valid_open
+-- 1 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 2 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 3 = 10009
+-- 4 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 5 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 6 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 7 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 8 = 10009
+-- 9 = 42
+-- 10 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 11 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 12 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 13 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 14 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 15 = 10009
+-- 16 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 17 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 18 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 19 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 20 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 21 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 22 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 23 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 24 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 25 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 26 = 10009
+-- 27 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 28 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 29 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 30 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 31 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 32 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 33 = 10009
+-- 34 = 42
+-- 35 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 36 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 37 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 38 = 10009
+-- 39 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 40 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 41 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 42 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 43 = 10009
+-- 44 = 42
+-- 45 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 46 = 10009
+-- 47 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 48 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 49 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 50 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 51 = 10009
+-- 52 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 53 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 54 = 10009
+-- 55 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 56 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 57 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 58 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
@@ -0,0 +1,8 @@
+WITH 
+`salaries$` as (SELECT * FROM salaries WHERE (valid_open < ? AND ? < valid_close)),
+`titles$` as (SELECT * FROM titles WHERE (valid_open < ? AND ? < valid_close)) 
+SELECT * FROM `titles$`
+-- 1 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 2 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 3 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 4 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
@@ -0,0 +1,9 @@
+WITH 
+salaries$ as (SELECT * FROM salaries WHERE (valid_open <= $1::timestamptz AND $2::timestamptz < valid_close AND txn_open <= $3::timestamptz AND $4::timestamptz < txn_close)),
+titles$ as (SELECT * FROM titles WHERE (valid_open <= $1::timestamptz AND $2::timestamptz < valid_close AND txn_open <= $3::timestamptz AND $4::timestamptz < txn_close)) 
+SELECT * FROM salaries$ WHERE emp_no = $5::bigint
+-- 1 = time.Date(1995, time.June, 1, 0, 0, 0, 0, time.UTC)
+-- 2 = time.Date(1995, time.June, 1, 0, 0, 0, 0, time.UTC)
+-- 3 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 4 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 5 = 10009
@@ -0,0 +1,13 @@
+-- Close the transaction period of every current record overlapping the update window, or touching it with the new
+-- values when coalescing
+UPDATE salaries
+SET txn_close = $1::timestamptz
+WHERE emp_no = $2::bigint
+  AND valid_open < $3::timestamptz
+  AND valid_close > $4::timestamptz
+  AND txn_open <= $1::timestamptz
+  AND txn_close > $1::timestamptz
+-- 1 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 2 = 10009
+-- 3 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 4 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
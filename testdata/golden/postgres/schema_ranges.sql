@@ -0,0 +1,115 @@
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+-- Employees table with bitemporal fields
+CREATE TABLE IF NOT EXISTS employees
+(
+    row_id           BIGINT  GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    emp_no           BIGINT  NOT NULL,
+    birth_date       TIMESTAMPTZ NOT NULL,
+    first_name       TEXT     NOT NULL,
+    last_name        TEXT     NOT NULL,
+    gender           TEXT     NOT NULL CHECK (gender IN ('M', 'F', 'O')),
+    hire_date        TIMESTAMPTZ NOT NULL,
+    -- Bitemporal fields
+    valid_open       TIMESTAMPTZ NOT NULL,
+    valid_close         TIMESTAMPTZ NOT NULL DEFAULT TIMESTAMPTZ '9999-12-31 23:59:59+00',
+    txn_open TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    txn_close   TIMESTAMPTZ NOT NULL DEFAULT TIMESTAMPTZ '9999-12-31 23:59:59+00',
+    valid_period     TSTZRANGE GENERATED ALWAYS AS (tstzrange(valid_open, valid_close)) STORED,
+    txn_period       TSTZRANGE GENERATED ALWAYS AS (tstzrange(txn_open, txn_close)) STORED
+);
+-- Indexes for bitemporal queries
+CREATE INDEX IF NOT EXISTS idx_employees_bitemporal ON employees (emp_no, valid_open, valid_close);
+CREATE INDEX IF NOT EXISTS idx_employees_transaction ON employees (emp_no, txn_open, txn_close);
+
+-- Departments table with bitemporal fields
+CREATE TABLE IF NOT EXISTS departments
+(
+    row_id           BIGINT  GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    dept_no          TEXT     NOT NULL,
+    dept_name        TEXT     NOT NULL,
+    -- Bitemporal fields
+    valid_open       TIMESTAMPTZ NOT NULL,
+    valid_close         TIMESTAMPTZ NOT NULL DEFAULT TIMESTAMPTZ '9999-12-31 23:59:59+00',
+    txn_open TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    txn_close   TIMESTAMPTZ NOT NULL DEFAULT TIMESTAMPTZ '9999-12-31 23:59:59+00',
+    valid_period     TSTZRANGE GENERATED ALWAYS AS (tstzrange(valid_open, valid_close)) STORED,
+    txn_period       TSTZRANGE GENERATED ALWAYS AS (tstzrange(txn_open, txn_close)) STORED
+);
+-- Indexes for bitemporal queries
+CREATE INDEX IF NOT EXISTS idx_departments_bitemporal ON departments (dept_no, valid_open, valid_close);
+CREATE INDEX IF NOT EXISTS idx_departments_transaction ON departments (dept_no, txn_open, txn_close);
+
+-- Department managers with bitemporal fields
+CREATE TABLE IF NOT EXISTS dept_manager
+(
+    row_id           BIGINT  GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    emp_no           BIGINT  NOT NULL,
+    dept_no          TEXT     NOT NULL,
+    -- Bitemporal fields
+    valid_open       TIMESTAMPTZ NOT NULL,
+    valid_close         TIMESTAMPTZ NOT NULL DEFAULT TIMESTAMPTZ '9999-12-31 23:59:59+00',
+    txn_open TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    txn_close   TIMESTAMPTZ NOT NULL DEFAULT TIMESTAMPTZ '9999-12-31 23:59:59+00',
+    valid_period     TSTZRANGE GENERATED ALWAYS AS (tstzrange(valid_open, valid_close)) STORED,
+    txn_period       TSTZRANGE GENERATED ALWAYS AS (tstzrange(txn_open, txn_close)) STORED
+);
+-- Indexes for bitemporal queries
+CREATE INDEX IF NOT EXISTS idx_dept_manager_bitemporal ON dept_manager (emp_no, dept_no, valid_open, valid_close);
+CREATE INDEX IF NOT EXISTS idx_dept_manager_transaction ON dept_manager (emp_no, dept_no, txn_open, txn_close);
+
+-- Department employees with bitemporal fields
+CREATE TABLE IF NOT EXISTS dept_emp
+(
+    row_id           BIGINT  GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    emp_no           BIGINT  NOT NULL,
+    dept_no          TEXT     NOT NULL,
+    -- Bitemporal fields
+    valid_open       TIMESTAMPTZ NOT NULL,
+    valid_close         TIMESTAMPTZ NOT NULL DEFAULT TIMESTAMPTZ '9999-12-31 23:59:59+00',
+    txn_open TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    txn_close   TIMESTAMPTZ NOT NULL DEFAULT TIMESTAMPTZ '9999-12-31 23:59:59+00',
+    valid_period     TSTZRANGE GENERATED ALWAYS AS (tstzrange(valid_open, valid_close)) STORED,
+    txn_period       TSTZRANGE GENERATED ALWAYS AS (tstzrange(txn_open, txn_close)) STORED
+);
+-- Indexes for bitemporal queries
+CREATE INDEX IF NOT EXISTS idx_dept_emp_bitemporal ON dept_emp (emp_no, dept_no, valid_open, valid_close);
+CREATE INDEX IF NOT EXISTS idx_dept_emp_transaction ON dept_emp (emp_no, dept_no, txn_open, txn_close);
+
+-- Titles with bitemporal fields
+CREATE TABLE IF NOT EXISTS titles
+(
+    row_id           BIGINT  GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    emp_no           BIGINT  NOT NULL,
+    title            TEXT     NOT NULL,
+    -- Bitemporal fields
+    valid_open       TIMESTAMPTZ NOT NULL,
+    valid_close         TIMESTAMPTZ NOT NULL DEFAULT TIMESTAMPTZ '9999-12-31 23:59:59+00',
+    txn_open TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    txn_close   TIMESTAMPTZ NOT NULL DEFAULT TIMESTAMPTZ '9999-12-31 23:59:59+00',
+    valid_period     TSTZRANGE GENERATED ALWAYS AS (tstzrange(valid_open, valid_close)) STORED,
+    txn_period       TSTZRANGE GENERATED ALWAYS AS (tstzrange(txn_open, txn_close)) STORED,
+    CONSTRAINT titles_no_overlap EXCLUDE USING gist (emp_no WITH =, valid_period WITH &&, txn_period WITH &&)
+);
+-- Indexes for bitemporal queries
+CREATE INDEX IF NOT EXISTS idx_titles_bitemporal ON titles (emp_no, valid_open, valid_close);
+CREATE INDEX IF NOT EXISTS idx_titles_transaction ON titles (emp_no, txn_open, txn_close);
+
+-- Salaries with bitemporal fields
+CREATE TABLE IF NOT EXISTS salaries
+(
+    row_id           BIGINT  GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    emp_no           BIGINT  NOT NULL,
+    salary           BIGINT  NOT NULL,
+    -- Bitemporal fields
+    valid_open       TIMESTAMPTZ NOT NULL,
+    valid_close         TIMESTAMPTZ NOT NULL DEFAULT TIMESTAMPTZ '9999-12-31 23:59:59+00',
+    txn_open TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    txn_close   TIMESTAMPTZ NOT NULL DEFAULT TIMESTAMPTZ '9999-12-31 23:59:59+00',
+    valid_period     TSTZRANGE GENERATED ALWAYS AS (tstzrange(valid_open, valid_close)) STORED,
+    txn_period       TSTZRANGE GENERATED ALWAYS AS (tstzrange(txn_open, txn_close)) STORED,
+    CONSTRAINT salaries_no_overlap EXCLUDE USING gist (emp_no WITH =, valid_period WITH &&, txn_period WITH &&)
+);
+-- Indexes for bitemporal queries
+CREATE INDEX IF NOT EXISTS idx_salaries_bitemporal ON salaries (emp_no, valid_open, valid_close);
+CREATE INDEX IF NOT EXISTS idx_salaries_transaction ON salaries (emp_no, txn_open, txn_close);
\ No newline at end of file
@@ -0,0 +1,96 @@
+-- Before segment: only create if there's actual time before updateStart
+SELECT
+    emp_no, salary,
+    valid_open           valid_open,
+    $1::timestamptz          valid_close,
+    $2::timestamptz          txn_open,
+    TIMESTAMPTZ '9999-12-31 23:59:59+00' txn_close
+FROM salaries
+WHERE emp_no = $3::bigint
+  AND valid_close > $1::timestamptz -- Neighbours are only rewritten when coalescing
+  AND valid_open < $1::timestamptz  -- Ensure non-zero duration
+  AND txn_open <= $2::timestamptz
+  AND txn_close > $2::timestamptz
+UNION ALL
+-- Update segment: the new values for the update window
+SELECT
+    $3::bigint as emp_no, $4::bigint as salary,
+    CASE WHEN valid_open  <  $1::timestamptz  THEN $1::timestamptz  ELSE valid_open  END valid_open,
+    CASE WHEN valid_close >= $5::timestamptz THEN $5::timestamptz ELSE valid_close END valid_close,
+    $2::timestamptz          txn_open,
+    TIMESTAMPTZ '9999-12-31 23:59:59+00' txn_close
+    FROM salaries
+    WHERE emp_no = $3::bigint
+    AND valid_open < $5::timestamptz
+    AND valid_close > $1::timestamptz
+    AND txn_open <= $2::timestamptz
+    AND txn_close > $2::timestamptz
+-- Ensure the calculated period has positive duration
+    AND   CASE WHEN valid_open  <  $1::timestamptz  THEN $1::timestamptz  ELSE valid_open  END
+        < CASE WHEN valid_close >= $5::timestamptz THEN $5::timestamptz ELSE valid_close END
+UNION ALL
+-- After segment: preserve portions of records that extend beyond updateEnd
+-- Only for records that START BEFORE updateEnd but extend beyond it
+SELECT
+    emp_no, salary,
+    $5::timestamptz         valid_open,
+    valid_close          valid_close,
+    $2::timestamptz          txn_open,
+    TIMESTAMPTZ '9999-12-31 23:59:59+00' txn_close
+FROM salaries
+WHERE emp_no = $3::bigint
+AND valid_open < $5::timestamptz          -- Must start BEFORE updateEnd, or AT it when coalescing
+AND valid_close > $5::timestamptz            -- Must end AFTER updateEnd
+AND $5::timestamptz < valid_close -- Ensure positive duration
+-- Explicit exclusion: do not include records that start exactly at updateEnd
+AND valid_open != $5::timestamptz
+AND txn_open <= $2::timestamptz
+AND txn_close > $2::timestamptz
+UNION ALL
+-- New period segment: create new record when update window has no overlap with existing data
+-- This handles cases where the update is entirely before or after existing data
+SELECT
+    $3::bigint as emp_no, $4::bigint as salary,
+    $1::timestamptz          valid_open,
+    $5::timestamptz         valid_close,
+    $2::timestamptz          txn_open,
+    TIMESTAMPTZ '9999-12-31 23:59:59+00' txn_close
+WHERE NOT EXISTS (
+SELECT 1 FROM salaries
+WHERE emp_no = $3::bigint
+    AND valid_open < $5::timestamptz
+    AND valid_close > $1::timestamptz
+    AND txn_open <= $2::timestamptz
+    AND txn_close > $2::timestamptz
+)
+UNION ALL
+-- Extension segment: create record for portion of update window before the earliest existing data
+SELECT
+    $3::bigint as emp_no, $4::bigint as salary,
+    $1::timestamptz          valid_open,
+    (
+        SELECT MIN(valid_open) valid_open
+        FROM salaries
+        WHERE emp_no = $3::bigint AND txn_open <= $2::timestamptz AND txn_close > $2::timestamptz
+    ) as valid_close,
+    $2::timestamptz          txn_open,
+    TIMESTAMPTZ '9999-12-31 23:59:59+00' txn_close
+WHERE $1::timestamptz < (
+    SELECT MIN(valid_open) FROM salaries
+    WHERE emp_no = $3::bigint
+    AND txn_open <= $2::timestamptz
+    AND txn_close > $2::timestamptz
+) AND EXISTS (
+    SELECT 1 FROM salaries
+    WHERE emp_no = $3::bigint
+        AND valid_open < $5::timestamptz
+        AND valid_close > $1::timestamptz
+        AND txn_open <= $2::timestamptz
+        AND txn_close > $2::timestamptz
+)
+ORDER BY valid_open
+-- 1 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 2 = time.Date(2025, time.September, 1, 12, 0, 0, 123456000, time.UTC)
+-- 3 = 10009
+-- 4 = 42
+-- 5 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
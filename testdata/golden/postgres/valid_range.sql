@@ -0,0 +1,6 @@
+WITH 
+salaries$ as (SELECT * FROM salaries WHERE (valid_open < $1::timestamptz AND $2::timestamptz < valid_close)),
+titles$ as (SELECT * FROM titles WHERE (valid_open < $1::timestamptz AND $2::timestamptz < valid_close)) 
+SELECT * FROM titles$
+-- 1 = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+-- 2 = time.Date(1995, time.January, 1, 0, 0, 0, 0, time.UTC)
@@ -0,0 +1,9 @@
+WITH 
+salaries$ as (SELECT * FROM salaries WHERE (valid_open <= @valid_open AND @valid_close < valid_close AND txn_open <= @txn_open AND @txn_close < txn_close)),
+titles$ as (SELECT * FROM titles WHERE (valid_open <= @valid_open AND @valid_close < valid_close AND txn_open <= @txn_open AND @txn_close < txn_close)) 
+SELECT * FROM salaries$ WHERE emp_no = @emp_no
+-- @emp_no = 10009
+-- @txn_close = "2025-09-01T12:00:00.123456Z"
+-- @txn_open = "2025-09-01T12:00:00.123456Z"
+-- @valid_close = "1995-06-01T00:00:00.000000Z"
+-- @valid_open = "1995-06-01T00:00:00.000000Z"
@@ -0,0 +1,79 @@
+-- Same segments as update_window.tmpl.sql, computed for every key in bitemporal_batch_keys at once
+-- Before segment: only create if there's actual time before updateStart
+SELECT
+    t.emp_no as emp_no, t.salary as salary,
+    t.valid_open         valid_open,
+    @valid_open          valid_close,
+    @txn_moment          txn_open,
+    '9999-12-31T23:59:59.000000Z' txn_close
+FROM salaries t
+JOIN bitemporal_batch_keys k ON k.emp_no = t.emp_no
+WHERE t.valid_close > @valid_open
+  AND t.valid_open < @valid_open  -- Ensure non-zero duration
+  AND t.txn_open <= @txn_moment
+  AND t.txn_close > @txn_moment
+UNION ALL
+-- Update segment: the new values, or the values computed from the superseded record, for the update window
+SELECT
+    t.emp_no as emp_no, @salary as salary,
+    CASE WHEN t.valid_open  <   @valid_open  THEN @valid_open  ELSE t.valid_open  END valid_open,
+    CASE WHEN t.valid_close >=  @valid_close THEN @valid_close ELSE t.valid_close END valid_close,
+    @txn_moment          txn_open,
+    '9999-12-31T23:59:59.000000Z' txn_close
+FROM salaries t
+JOIN bitemporal_batch_keys k ON k.emp_no = t.emp_no
+WHERE t.valid_open < @valid_close
+  AND t.valid_close > @valid_open
+  AND t.txn_open <= @txn_moment
+  AND t.txn_close > @txn_moment
+UNION ALL
+-- After segment: preserve portions of records that start before updateEnd and extend beyond it
+SELECT
+    t.emp_no as emp_no, t.salary as salary,
+    @valid_close         valid_open,
+    t.valid_close        valid_close,
+    @txn_moment          txn_open,
+    '9999-12-31T23:59:59.000000Z' txn_close
+FROM salaries t
+JOIN bitemporal_batch_keys k ON k.emp_no = t.emp_no
+WHERE t.valid_open < @valid_close
+  AND t.valid_close > @valid_close
+  AND t.txn_open <= @txn_moment
+  AND t.txn_close > @txn_moment
+UNION ALL
+-- New period segment: keys with no current record overlapping the update window
+SELECT
+    k.emp_no as emp_no, @salary as salary,
+    @valid_open          valid_open,
+    @valid_close         valid_close,
+    @txn_moment          txn_open,
+    '9999-12-31T23:59:59.000000Z' txn_close
+FROM bitemporal_batch_keys k
+WHERE NOT EXISTS (
+    SELECT 1 FROM salaries t
+    WHERE k.emp_no = t.emp_no
+      AND t.valid_open < @valid_close
+      AND t.valid_close > @valid_open
+      AND t.txn_open <= @txn_moment
+      AND t.txn_close > @txn_moment
+)
+UNION ALL
+-- Extension segment: the portion of the update window before the earliest record of each key
+SELECT
+    k.emp_no as emp_no, @salary as salary,
+    @valid_open          valid_open,
+    MIN(t.valid_open)    valid_close,
+    @txn_moment          txn_open,
+    '9999-12-31T23:59:59.000000Z' txn_close
+FROM bitemporal_batch_keys k
+JOIN salaries t ON k.emp_no = t.emp_no
+WHERE t.txn_open <= @txn_moment
+  AND t.txn_close > @txn_moment
+GROUP BY k.emp_no
+HAVING @valid_open < MIN(t.valid_open)
+   AND MIN(t.valid_open) < @valid_close
+ORDER BY emp_no, valid_open
+-- @salary = 42
+-- @txn_moment = "2025-09-01T12:00:00.123456Z"
+-- @valid_close = "2000-01-01T00:00:00.000000Z"
+-- @valid_open = "1995-01-01T00:00:00.000000Z"
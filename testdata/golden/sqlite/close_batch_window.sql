@@ -0,0 +1,12 @@
+-- Close the transaction period of every current record of the keys in bitemporal_batch_keys overlapping the update window
+UPDATE salaries AS t
+SET txn_close = @txn_moment
+WHERE EXISTS (SELECT 1 FROM bitemporal_batch_keys k WHERE k.emp_no = t.emp_no)
+  AND t.valid_open < @valid_close
+  AND t.valid_close > @valid_open
+  AND t.txn_open <= @txn_moment
+  AND t.txn_close > @txn_moment
+-- @salary = 42
+-- @txn_moment = "2025-09-01T12:00:00.123456Z"
+-- @valid_close = "2000-01-01T00:00:00.000000Z"
+-- @valid_open = "1995-01-01T00:00:00.000000Z"
@@ -0,0 +1,14 @@
+-- Close the transaction period of every current record overlapping the update window, or touching it with the new
+-- values when coalescing
+UPDATE salaries
+SET txn_close = @txn_moment
+WHERE emp_no = @emp_no
+  AND valid_open < @valid_close
+  AND valid_close > @valid_open
+  AND txn_open <= @txn_moment
+  AND txn_close > @txn_moment
+-- @emp_no = 10009
+-- @salary = 42
+-- @txn_moment = "2025-09-01T12:00:00.123456Z"
+-- @valid_close = "2000-01-01T00:00:00.000000Z"
+-- @valid_open = "1995-01-01T00:00:00.000000Z"
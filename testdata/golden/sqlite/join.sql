@@ -0,0 +1,17 @@
+WITH 
+salaries$ as (SELECT * FROM salaries WHERE (valid_open <= @valid_open AND @valid_close < valid_close AND txn_open <= @txn_open AND @txn_close < txn_close)),
+titles$ as (SELECT * FROM titles WHERE (valid_open <= @valid_open AND @valid_close < valid_close AND txn_open <= @txn_open AND @txn_close < txn_close)) 
+SELECT l.emp_no, l.salary, r.emp_no, r.title,
+    MAX(l.valid_open, r.valid_open) valid_open,
+    MIN(l.valid_close, r.valid_close) valid_close,
+    MAX(l.txn_open, r.txn_open) txn_open,
+    MIN(l.txn_close, r.txn_close) txn_close
+FROM salaries$ l
+JOIN titles$ r ON l.emp_no = r.emp_no
+  AND l.valid_open < r.valid_close AND r.valid_open < l.valid_close
+  AND l.txn_open < r.txn_close AND r.txn_open < l.txn_close
+ORDER BY l.emp_no, valid_open, txn_open
+-- @txn_close = "2025-09-01T12:00:00.123456Z"
+-- @txn_open = "2025-09-01T12:00:00.123456Z"
+-- @valid_close = "1995-06-01T00:00:00.000000Z"
+-- @valid_open = "1995-06-01T00:00:00.000000Z"
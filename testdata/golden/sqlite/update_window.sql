@@ -0,0 +1,96 @@
+-- Before segment: only create if there's actual time before updateStart
+SELECT
+    emp_no, salary,
+    valid_open           valid_open,
+    @valid_open          valid_close,
+    @txn_moment          txn_open,
+    '9999-12-31T23:59:59.000000Z' txn_close
+FROM salaries
+WHERE emp_no = @emp_no
+  AND valid_close > @valid_open -- Neighbours are only rewritten when coalescing
+  AND valid_open < @valid_open  -- Ensure non-zero duration
+  AND txn_open <= @txn_moment
+  AND txn_close > @txn_moment
+UNION ALL
+-- Update segment: the new values for the update window
+SELECT
+    @emp_no as emp_no, @salary as salary,
+    CASE WHEN valid_open  <  @valid_open  THEN @valid_open  ELSE valid_open  END valid_open,
+    CASE WHEN valid_close >= @valid_close THEN @valid_close ELSE valid_close END valid_close,
+    @txn_moment          txn_open,
+    '9999-12-31T23:59:59.000000Z' txn_close
+    FROM salaries
+    WHERE emp_no = @emp_no
+    AND valid_open < @valid_close
+    AND valid_close > @valid_open
+    AND txn_open <= @txn_moment
+    AND txn_close > @txn_moment
+-- Ensure the calculated period has positive duration
+    AND   CASE WHEN valid_open  <  @valid_open  THEN @valid_open  ELSE valid_open  END
+        < CASE WHEN valid_close >= @valid_close THEN @valid_close ELSE valid_close END
+UNION ALL
+-- After segment: preserve portions of records that extend beyond updateEnd
+-- Only for records that START BEFORE updateEnd but extend beyond it
+SELECT
+    emp_no, salary,
+    @valid_close         valid_open,
+    valid_close          valid_close,
+    @txn_moment          txn_open,
+    '9999-12-31T23:59:59.000000Z' txn_close
+FROM salaries
+WHERE emp_no = @emp_no
+AND valid_open < @valid_close          -- Must start BEFORE updateEnd, or AT it when coalescing
+AND valid_close > @valid_close            -- Must end AFTER updateEnd
+AND @valid_close < valid_close -- Ensure positive duration
+-- Explicit exclusion: do not include records that start exactly at updateEnd
+AND valid_open != @valid_close
+AND txn_open <= @txn_moment
+AND txn_close > @txn_moment
+UNION ALL
+-- New period segment: create new record when update window has no overlap with existing data
+-- This handles cases where the update is entirely before or after existing data
+SELECT
+    @emp_no as emp_no, @salary as salary,
+    @valid_open          valid_open,
+    @valid_close         valid_close,
+    @txn_moment          txn_open,
+    '9999-12-31T23:59:59.000000Z' txn_close
+WHERE NOT EXISTS (
+SELECT 1 FROM salaries
+WHERE emp_no = @emp_no
+    AND valid_open < @valid_close
+    AND valid_close > @valid_open
+    AND txn_open <= @txn_moment
+    AND txn_close > @txn_moment
+)
+UNION ALL
+-- Extension segment: create record for portion of update window before the earliest existing data
+SELECT
+    @emp_no as emp_no, @salary as salary,
+    @valid_open          valid_open,
+    (
+        SELECT MIN(valid_open) valid_open
+        FROM salaries
+        WHERE emp_no = @emp_no AND txn_open <= @txn_moment AND txn_close > @txn_moment
+    ) as valid_close,
+    @txn_moment          txn_open,
+    '9999-12-31T23:59:59.000000Z' txn_close
+WHERE @valid_open < (
+    SELECT MIN(valid_open) FROM salaries
+    WHERE emp_no = @emp_no
+    AND txn_open <= @txn_moment
+    AND txn_close > @txn_moment
+) AND EXISTS (
+    SELECT 1 FROM salaries
+    WHERE emp_no = @emp_no
+        AND valid_open < @valid_close
+        AND valid_close > @valid_open
+        AND txn_open <= @txn_moment
+        AND txn_close > @txn_moment
+)
+ORDER BY valid_open
+-- @emp_no = 10009
+-- @salary = 42
+-- @txn_moment = "2025-09-01T12:00:00.123456Z"
+-- @valid_close = "2000-01-01T00:00:00.000000Z"
+-- @valid_open = "1995-01-01T00:00:00.000000Z"
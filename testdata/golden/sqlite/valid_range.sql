@@ -0,0 +1,6 @@
+WITH 
+salaries$ as (SELECT * FROM salaries WHERE (valid_open < @valid_range_close AND @valid_range_open < valid_close)),
+titles$ as (SELECT * FROM titles WHERE (valid_open < @valid_range_close AND @valid_range_open < valid_close)) 
+SELECT * FROM titles$
+-- @valid_range_close = "2000-01-01T00:00:00.000000Z"
+-- @valid_range_open = "1995-01-01T00:00:00.000000Z"
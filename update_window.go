@@ -2,9 +2,11 @@ package bitemporal
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	_ "embed"
 	"fmt"
+	"slices"
 	"strings"
 	"text/template"
 	"time"
@@ -13,16 +15,20 @@ import (
 //go:embed sql/update_window.tmpl.sql
 var createUpdateWindowQuery string
 
+//go:embed sql/close_window.tmpl.sql
+var closeUpdateWindowQuery string
+
+//go:embed sql/coalesce_periods.tmpl.sql
+var coalescePeriodsQuery string
+
 type QueryFragment struct {
 	Query  string
 	ArgMap map[string]any
 }
 
+// Args binds the named arguments for SQLite, see Dialect.Bind for other databases
 func (q QueryFragment) Args() []any {
-	args := make([]any, 0, len(q.ArgMap))
-	for k, v := range q.ArgMap {
-		args = append(args, sql.Named(k, v))
-	}
+	_, args := SQLite.Bind(q.Query, q.ArgMap)
 	return args
 }
 
@@ -34,6 +40,21 @@ type UpdateWindow struct {
 
 	ValidFrom time.Time
 	ValidTo   time.Time
+
+	// TxnMoment is the transaction moment the new periods are recorded at. When zero ApplyUpdateWindow uses the
+	// TxnMoment of the TemporalContext or the Clock of the TemporalDB, and CreatePeriodsQuery the wall clock.
+	TxnMoment time.Time
+
+	// Delete removes the window from valid time instead of writing Values over it, only the FilterBy values are needed
+	Delete bool
+
+	// Coalesce merges adjacent periods holding the same values, including the current records touching the window
+	// with the new Values
+	Coalesce bool
+
+	// ExpectedVersion makes ApplyUpdateWindow fail with ErrVersionConflict unless the FilterBy key is still at this
+	// version, see TemporalDB.Version. It is not checked when zero.
+	ExpectedVersion time.Time
 }
 
 func (w UpdateWindow) ColumnsString() string {
@@ -48,6 +69,30 @@ func (w UpdateWindow) ColumnParamsString() string {
 	return strings.Join(params, ", ")
 }
 
+// SameValuesString matches records holding the new values in every selected column but the filters, only such
+// neighbours touching the window are rewritten when coalescing
+func (w UpdateWindow) SameValuesString() string {
+	var values []string
+	for _, column := range w.Select {
+		if !slices.Contains(w.FilterBy, column) {
+			values = append(values, column+" = @"+column)
+		}
+	}
+	if len(values) == 0 {
+		return "TRUE"
+	}
+	return strings.Join(values, " AND ")
+}
+
+// keyString formats the FilterBy values of the window, e.g. salaries{emp_no=10009}
+func (w UpdateWindow) keyString() string {
+	key := make([]string, len(w.FilterBy))
+	for i, column := range w.FilterBy {
+		key[i] = fmt.Sprintf("%s=%v", column, w.Values[column])
+	}
+	return fmt.Sprintf("%s{%s}", w.Table, strings.Join(key, ", "))
+}
+
 func (w UpdateWindow) FiltersString() string {
 	filters := make([]string, len(w.FilterBy))
 	for i := range w.FilterBy {
@@ -56,29 +101,73 @@ func (w UpdateWindow) FiltersString() string {
 	return strings.Join(filters, " AND ")
 }
 
+// CreatePeriodsQuery renders the records that replace the current records overlapping the window, for SQLite
 func CreatePeriodsQuery(window UpdateWindow) (QueryFragment, error) {
-	tmpl, err := template.New("update").Parse(createUpdateWindowQuery)
+	return createPeriodsQuery(SQLite, window)
+}
+
+func createPeriodsQuery(dialect Dialect, window UpdateWindow) (QueryFragment, error) {
+	fragment, err := renderWindowQuery(dialect, createUpdateWindowQuery, window)
+	if err != nil || !window.Coalesce {
+		return fragment, err
+	}
+
+	tmpl, err := template.New("coalesce").Parse(coalescePeriodsQuery)
+	if err != nil {
+		return QueryFragment{}, err
+	}
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, struct {
+		UpdateWindow
+		Dialect Dialect
+		Periods string
+	}{window, dialect, fragment.Query})
+	if err != nil {
+		return QueryFragment{}, err
+	}
+	fragment.Query = buf.String()
+	return fragment, nil
+}
+
+func renderWindowQuery(dialect Dialect, text string, window UpdateWindow) (QueryFragment, error) {
+	tmpl, err := template.New("update").Parse(text)
 	if err != nil {
 		return QueryFragment{}, err
 	}
 
+	if !window.ValidFrom.Before(window.ValidTo) {
+		return QueryFragment{}, fmt.Errorf("update window on %q must be valid from before %s, got %s",
+			window.Table, window.ValidTo.Format(time.DateTime), window.ValidFrom.Format(time.DateTime))
+	}
+	if window.TxnMoment.IsZero() {
+		window.TxnMoment = time.Now()
+	}
+
 	fragment := QueryFragment{
 		ArgMap: map[string]any{
 			"valid_open":  window.ValidFrom,
 			"valid_close": window.ValidTo,
+			"txn_moment":  window.TxnMoment,
 		},
 	}
 
-	for i := range window.Select {
-		val, ok := window.Values[window.Select[i]]
+	columns := window.Select
+	if window.Delete {
+		columns = window.FilterBy
+	}
+	for i := range columns {
+		val, ok := window.Values[columns[i]]
 		if !ok {
-			return QueryFragment{}, fmt.Errorf("value not found for column %q", window.Select[i])
+			return QueryFragment{}, fmt.Errorf("value not found for column %q", columns[i])
 		}
-		fragment.ArgMap[window.Select[i]] = val
+		fragment.ArgMap[columns[i]] = val
 	}
 
 	var buf bytes.Buffer
-	err = tmpl.Execute(&buf, window)
+	err = tmpl.Execute(&buf, struct {
+		UpdateWindow
+		Dialect Dialect
+	}{window, dialect})
 	if err != nil {
 		return QueryFragment{}, err
 	}
@@ -87,3 +176,184 @@ func CreatePeriodsQuery(window UpdateWindow) (QueryFragment, error) {
 
 	return fragment, nil
 }
+
+// Period is a single record written by an update window
+type Period struct {
+	Values map[string]any `json:"values"`
+	Entity
+}
+
+// ApplyUpdateWindow persists the window: the transaction period of every current record overlapping the window
+// is closed and the periods produced by CreatePeriodsQuery are inserted in their place. Everything happens
+// inside a single transaction so all records share one transaction moment.
+func (repo *TemporalDB) ApplyUpdateWindow(ctx context.Context, window UpdateWindow) ([]Period, error) {
+	if window.TxnMoment.IsZero() {
+		window.TxnMoment = repo.txnMoment(ctx)
+	}
+
+	periodsFragment, err := createPeriodsQuery(repo.dialect, window)
+	if err != nil {
+		return nil, err
+	}
+	closeFragment, err := renderWindowQuery(repo.dialect, closeUpdateWindowQuery, window)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if !window.ExpectedVersion.IsZero() {
+		version, err := repo.keyVersion(ctx, tx, window.Table, window.FilterBy, window.Values)
+		if err != nil {
+			return nil, err
+		}
+		if !version.Equal(window.ExpectedVersion) {
+			return nil, fmt.Errorf("%w: %s is at version %s, not %s", ErrVersionConflict, window.keyString(),
+				version.Format(time.RFC3339Nano), window.ExpectedVersion.Format(time.RFC3339Nano))
+		}
+	}
+
+	// the new periods must be computed before the current records are closed
+	records, err := repo.queryRecords(ctx, tx, periodsFragment, len(window.Select)+4)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args := repo.bind(closeFragment)
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+		// SQLite only takes the write lock here, after the version was read. A writer holding the same version that
+		// took it first leaves this one unable to upgrade its lock.
+		if !window.ExpectedVersion.IsZero() && isBusy(err) {
+			return nil, fmt.Errorf("%w: %s was written concurrently at version %s", ErrVersionConflict, window.keyString(),
+				window.ExpectedVersion.Format(time.RFC3339Nano))
+		}
+		return nil, err
+	}
+
+	if err = repo.insertRecords(ctx, tx, window.Table, window.Select, records); err != nil {
+		return nil, err
+	}
+
+	periods := make([]Period, 0, len(records))
+	for _, record := range records {
+		period, err := recordToPeriod(window.Select, record)
+		if err != nil {
+			return nil, err
+		}
+		periods = append(periods, period)
+	}
+
+	key := make(map[string]any, len(window.FilterBy))
+	for _, column := range window.FilterBy {
+		key[column] = window.Values[column]
+	}
+	err = repo.preCommit(ctx, tx, Write{
+		Table:     window.Table,
+		Keys:      []map[string]any{key},
+		ValidFrom: window.ValidFrom,
+		ValidTo:   window.ValidTo,
+		TxnMoment: window.TxnMoment,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return periods, tx.Commit()
+}
+
+func (repo *TemporalDB) queryRecords(ctx context.Context, tx *sql.Tx, fragment QueryFragment, width int) ([][]any, error) {
+	query, args := repo.bind(fragment)
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records [][]any
+	for rows.Next() {
+		record := make([]any, width)
+		ptrs := make([]any, width)
+		for i := range record {
+			ptrs[i] = &record[i]
+		}
+		if err = rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// insertRecords inserts records of the columns followed by the four temporal columns into table
+func (repo *TemporalDB) insertRecords(ctx context.Context, tx *sql.Tx, table string, columns []string, records [][]any) error {
+	columns = append(slices.Clip(columns), temporalColumns...)
+	params := make([]string, len(columns))
+	for i, column := range columns {
+		params[i] = "@" + column
+	}
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(columns, ", "), strings.Join(params, ", "))
+
+	var stmt *sql.Stmt
+	for _, record := range records {
+		values := make(map[string]any, len(columns))
+		for i, column := range columns {
+			values[column] = record[i]
+		}
+		for _, column := range temporalColumns {
+			moment, err := asMoment(values[column])
+			if err != nil {
+				return err
+			}
+			values[column] = moment
+		}
+
+		query, args := repo.bind(QueryFragment{insert, values})
+		if stmt == nil {
+			var err error
+			if stmt, err = tx.PrepareContext(ctx, query); err != nil {
+				return err
+			}
+			defer stmt.Close()
+		}
+		if _, err := stmt.ExecContext(ctx, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordToPeriod expects the record to be the selected columns followed by the four temporal columns
+func recordToPeriod(columns []string, record []any) (Period, error) {
+	period := Period{Values: make(map[string]any, len(columns))}
+	for i, column := range columns {
+		period.Values[column] = record[i]
+	}
+
+	moments := []*time.Time{&period.ValidOpen, &period.ValidClose, &period.TxnOpen, &period.TxnClose}
+	for i, moment := range moments {
+		t, err := asMoment(record[len(columns)+i])
+		if err != nil {
+			return Period{}, err
+		}
+		*moment = t
+	}
+	return period, nil
+}
+
+// asMoment converts a scanned temporal column, which SQLite hands back as text for computed columns, to UTC
+func asMoment(v any) (time.Time, error) {
+	switch v := v.(type) {
+	case time.Time:
+		return v.UTC(), nil
+	case []byte:
+		return asMoment(string(v))
+	case string:
+		if t, err := ParseTime(v); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unexpected temporal value %v (%T)", v, v)
+}
@@ -6,10 +6,11 @@ import (
 	_ "embed"
 	"fmt"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
-	_ "github.com/mattn/go-sqlite3"
 	"github.com/olekukonko/tablewriter"
 	"github.com/pborges/bitemporal"
 )
@@ -22,6 +23,9 @@ var schema string
 //go:embed sql/test_window_data.sql
 var tempDataQuery string
 
+// testTxnMoment is after the test data was recorded
+var testTxnMoment = bitemporal.AsTime("2025-09-01 12:00:00")
+
 type SalaryRow struct {
 	EmpNo           int64
 	Salary          int64
@@ -64,10 +68,13 @@ func getSalariesUpdateWindow(t *testing.T, db *sql.DB, empNo int64, salary int64
 	var salaryRows []SalaryRow
 	for rows.Next() {
 		var row SalaryRow
-		err = rows.Scan(&row.EmpNo, &row.Salary, &row.ValidFrom, &row.ValidTo, &row.TransactionFrom, &row.TransactionTo)
+		var moments [4]any
+		err = rows.Scan(&row.EmpNo, &row.Salary, &moments[0], &moments[1], &moments[2], &moments[3])
 		if err != nil {
 			t.Fatalf("Failed to scan row: %v", err)
 		}
+		row.ValidFrom, row.ValidTo = dateTime(t, moments[0]), dateTime(t, moments[1])
+		row.TransactionFrom, row.TransactionTo = dateTime(t, moments[2]), dateTime(t, moments[3])
 		salaryRows = append(salaryRows, row)
 	}
 	if debug {
@@ -76,11 +83,29 @@ func getSalariesUpdateWindow(t *testing.T, db *sql.DB, empNo int64, salary int64
 	return salaryRows
 }
 
+// dateTime formats a scanned period column, stored columns come back as times and computed ones as text
+func dateTime(t *testing.T, v any) string {
+	switch v := v.(type) {
+	case time.Time:
+		return v.UTC().Format(time.DateTime)
+	case string:
+		moment, err := bitemporal.ParseTime(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return moment.Format(time.DateTime)
+	}
+	t.Fatalf("unexpected period value %v (%T)", v, v)
+	return ""
+}
+
 func createTestDB(t *testing.T) (*sql.DB, func()) {
-	db, err := sql.Open("sqlite3", ":memory:")
+	db, err := sql.Open(bitemporal.SQLiteDriver, ":memory:")
 	if err != nil {
 		t.Fatalf("Failed to open in-memory database: %v", err)
 	}
+	// every connection to :memory: is a new database
+	db.SetMaxOpenConns(1)
 	_, err = db.Exec(schema)
 	if err != nil {
 		t.Fatalf("Failed to execute schema: %v", err)
@@ -89,6 +114,14 @@ func createTestDB(t *testing.T) (*sql.DB, func()) {
 	if err != nil {
 		t.Fatalf("Failed to execute tempDataQuery: %v", err)
 	}
+	// the test data predates the canonical encoding
+	temporalDB, err := bitemporal.NewTemporalDB(db)
+	if err != nil {
+		t.Fatalf("Failed to create TemporalDB: %v", err)
+	}
+	if _, err = temporalDB.MigratePeriods(context.Background()); err != nil {
+		t.Fatalf("Failed to migrate tempDataQuery: %v", err)
+	}
 	return db, func() {
 		db.Close()
 	}
@@ -334,7 +367,7 @@ func TestUpdateWindowEntirelyBeforeExistingData(t *testing.T) {
 	validateTable(t, rows)
 }
 
-// TestRowCollapse verifies that the
+// TestRowCollapse verifies that the periods written over the update window are coalesced into one
 func TestRowCollapse(t *testing.T) {
 	db, cleanup := createTestDB(t)
 	defer cleanup()
@@ -351,14 +384,13 @@ func TestRowCollapse(t *testing.T) {
 		ValidFrom: bitemporal.AsTime(validFrom),
 		ValidTo:   bitemporal.AsTime(validTo),
 		Values:    map[string]interface{}{"emp_no": empNo, "salary": salary},
+		Coalesce:  true,
 	})
 	if err != nil {
 		t.Error(err)
 	}
 
-	query := fmt.Sprintf("SELECT emp_no,salary,min(valid_open),max(valid_close),txn_open,txn_close FROM (%s) GROUP BY emp_no,salary ORDER BY valid_open", frag.Query)
-
-	rows, err := db.Query(query, frag.Args()...)
+	rows, err := db.Query(frag.Query, frag.Args()...)
 	if err != nil {
 		t.Fatalf("Failed to execute query: %v", err)
 	}
@@ -367,10 +399,13 @@ func TestRowCollapse(t *testing.T) {
 	var salaryRows []SalaryRow
 	for rows.Next() {
 		var row SalaryRow
-		err = rows.Scan(&row.EmpNo, &row.Salary, &row.ValidFrom, &row.ValidTo, &row.TransactionFrom, &row.TransactionTo)
+		var moments [4]any
+		err = rows.Scan(&row.EmpNo, &row.Salary, &moments[0], &moments[1], &moments[2], &moments[3])
 		if err != nil {
 			t.Fatalf("Failed to scan row: %v", err)
 		}
+		row.ValidFrom, row.ValidTo = dateTime(t, moments[0]), dateTime(t, moments[1])
+		row.TransactionFrom, row.TransactionTo = dateTime(t, moments[2]), dateTime(t, moments[3])
 		salaryRows = append(salaryRows, row)
 	}
 	if debug {
@@ -664,3 +699,298 @@ func TestUpdatePreservingHistory(t *testing.T) {
 	t.Log("After Update")
 	printMap(columns, res)
 }
+
+func currentSalaries(t *testing.T, db *sql.DB, empNo int64) []SalaryRow {
+	rows, err := db.Query(`SELECT emp_no, salary, DATETIME(valid_open), DATETIME(valid_close), txn_open, txn_close FROM salaries
+		WHERE emp_no = @emp_no AND DATETIME(txn_close) = DATETIME('9999-12-31 23:59:59') ORDER BY DATETIME(valid_open)`,
+		sql.Named("emp_no", empNo))
+	if err != nil {
+		t.Fatalf("Failed to query salaries: %v", err)
+	}
+	defer rows.Close()
+
+	var salaryRows []SalaryRow
+	for rows.Next() {
+		var row SalaryRow
+		err = rows.Scan(&row.EmpNo, &row.Salary, &row.ValidFrom, &row.ValidTo, &row.TransactionFrom, &row.TransactionTo)
+		if err != nil {
+			t.Fatalf("Failed to scan row: %v", err)
+		}
+		salaryRows = append(salaryRows, row)
+	}
+	return salaryRows
+}
+
+func TestApplyUpdateWindow(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	temporalDB, err := bitemporal.NewTemporalDB(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txnMoment := bitemporal.AsTime("2025-09-01 12:00:00")
+	periods, err := temporalDB.ApplyUpdateWindow(context.Background(), bitemporal.UpdateWindow{
+		Table:     "salaries",
+		Select:    []string{"emp_no", "salary"},
+		FilterBy:  []string{"emp_no"},
+		ValidFrom: bitemporal.AsTime("1995-01-01"),
+		ValidTo:   bitemporal.AsTime("2000-01-01"),
+		TxnMoment: txnMoment,
+		Values:    map[string]any{"emp_no": 10009, "salary": 42},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, period := range periods {
+		if !period.TxnOpen.Equal(txnMoment) {
+			t.Errorf("Expected period %s to be recorded at %s", period.Entity, txnMoment)
+		}
+	}
+
+	// 1994-02-16 -> 2000-02-15 is covered by 6 records, all of which are superseded
+	var closed int
+	err = db.QueryRow("SELECT COUNT(*) FROM salaries WHERE DATETIME(txn_close) = DATETIME(@txn_moment)",
+		sql.Named("txn_moment", txnMoment)).Scan(&closed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if closed != 6 {
+		t.Errorf("Expected 6 records to have their transaction period closed, got %d", closed)
+	}
+
+	rows := currentSalaries(t, db, 10009)
+	if debug {
+		PrintSalaryTable(42, "1995-01-01", "2000-01-01", rows)
+	}
+
+	if len(rows) != 18-6+len(periods) {
+		t.Errorf("Expected %d current records, got %d", 18-6+len(periods), len(rows))
+	}
+	for _, row := range rows {
+		if row.ValidFrom >= "1995-01-01 00:00:00" && row.ValidTo <= "2000-01-01 00:00:00" && row.Salary != 42 {
+			t.Errorf("Expected salary 42 for row with ValidFrom=%s ValidTo=%s, got %d", row.ValidFrom, row.ValidTo, row.Salary)
+		}
+	}
+	validateTable(t, rows)
+}
+
+func TestCoalesceOnlyEqualNeighbours(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	temporalDB, err := bitemporal.NewTemporalDB(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the window replaces exactly the 80944 record, 78335 touches it before and 82507 after
+	window := bitemporal.UpdateWindow{
+		Table:     "salaries",
+		Select:    []string{"emp_no", "salary"},
+		FilterBy:  []string{"emp_no"},
+		ValidFrom: bitemporal.AsTime("1995-02-16"),
+		ValidTo:   bitemporal.AsTime("1996-02-16"),
+		TxnMoment: bitemporal.AsTime("2025-09-01 12:00:00"),
+		Values:    map[string]any{"emp_no": 10009, "salary": 42},
+		Coalesce:  true,
+	}
+	periods, err := temporalDB.ApplyUpdateWindow(context.Background(), window)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(periods) != 1 || periods[0].Values["salary"] != int64(42) {
+		t.Errorf("Expected only the window to be written, got %v", periods)
+	}
+
+	var closed int
+	err = db.QueryRow("SELECT COUNT(*) FROM salaries WHERE DATETIME(txn_close) = DATETIME(@txn_moment)",
+		sql.Named("txn_moment", window.TxnMoment)).Scan(&closed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if closed != 1 {
+		t.Errorf("Expected the neighbours holding other salaries to be left alone, got %d records closed", closed)
+	}
+
+	// a neighbour holding the new values is merged with the window
+	window.TxnMoment = bitemporal.AsTime("2025-09-02 12:00:00")
+	window.Values = map[string]any{"emp_no": 10009, "salary": 78335}
+	periods, err = temporalDB.ApplyUpdateWindow(context.Background(), window)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(periods) != 1 || !periods[0].ValidOpen.Equal(bitemporal.AsTime("1994-02-16")) || !periods[0].ValidClose.Equal(bitemporal.AsTime("1996-02-16")) {
+		t.Errorf("Expected 78335 to be merged from 1994-02-16 to 1996-02-16, got %v", periods)
+	}
+	validateTable(t, currentSalaries(t, db, 10009))
+}
+
+func TestCoalesceKeepsNonContiguousRepeats(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	temporalDB, err := bitemporal.NewTemporalDB(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	window := bitemporal.UpdateWindow{
+		Table:     "salaries",
+		Select:    []string{"emp_no", "salary"},
+		FilterBy:  []string{"emp_no"},
+		ValidFrom: bitemporal.AsTime("2000-01-01"),
+		ValidTo:   bitemporal.AsTime("2000-02-15"),
+		TxnMoment: bitemporal.AsTime("2025-09-01 12:00:00"),
+		Values:    map[string]any{"emp_no": 10009, "salary": 78335},
+		Coalesce:  true,
+	}
+	if _, err = temporalDB.ApplyUpdateWindow(context.Background(), window); err != nil {
+		t.Fatal(err)
+	}
+
+	// 78335 now appears either side of the new window, they must not be merged across it
+	window.ValidFrom, window.ValidTo = bitemporal.AsTime("1995-01-01"), bitemporal.AsTime("2000-01-01")
+	window.TxnMoment = bitemporal.AsTime("2025-09-02 12:00:00")
+	window.Values = map[string]any{"emp_no": 10009, "salary": 42}
+	periods, err := temporalDB.ApplyUpdateWindow(context.Background(), window)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		salary   int64
+		from, to string
+	}{
+		{78335, "1994-02-16", "1995-01-01"},
+		{42, "1995-01-01", "2000-01-01"},
+	}
+	if len(periods) != len(expected) {
+		t.Fatalf("Expected %d periods, got %d", len(expected), len(periods))
+	}
+	for i, e := range expected {
+		p := periods[i]
+		if p.Values["salary"] != e.salary || !p.ValidOpen.Equal(bitemporal.AsTime(e.from)) || !p.ValidClose.Equal(bitemporal.AsTime(e.to)) {
+			t.Errorf("Expected salary %d from %s to %s, got %v %s", e.salary, e.from, e.to, p.Values["salary"], p.Entity)
+		}
+	}
+
+	rows := currentSalaries(t, db, 10009)
+	if debug {
+		PrintSalaryTable(42, "1995-01-01", "2000-01-01", rows)
+	}
+	// the first write split one record in two, the second replaced 6 records with 2 and left the touching 78335
+	// record written by the first alone
+	if len(rows) != 18+1-6+2 {
+		t.Errorf("Expected %d current records, got %d", 18+1-6+2, len(rows))
+	}
+	for _, row := range rows {
+		if row.ValidFrom == "2000-01-01 00:00:00" && !strings.HasPrefix(row.TransactionFrom, "2025-09-01") {
+			t.Errorf("Expected the 78335 record from 2000-01-01 to keep its first write, got %v", row)
+		}
+	}
+	validateTable(t, rows)
+}
+
+func TestInvertedWindow(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	temporalDB, err := bitemporal.NewTemporalDB(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := currentSalaries(t, db, 10009)
+
+	_, err = temporalDB.ApplyUpdateWindow(context.Background(), bitemporal.UpdateWindow{
+		Table:     "salaries",
+		Select:    []string{"emp_no", "salary"},
+		FilterBy:  []string{"emp_no"},
+		ValidFrom: bitemporal.AsTime("1999-01-01"),
+		ValidTo:   bitemporal.AsTime("1995-01-01"),
+		TxnMoment: testTxnMoment,
+		Values:    map[string]any{"emp_no": 10009, "salary": 42},
+	})
+	if err == nil {
+		t.Fatal("Expected a window valid from after it is valid to to be rejected")
+	}
+
+	if after := currentSalaries(t, db, 10009); !reflect.DeepEqual(before, after) {
+		t.Errorf("Expected the rejected window to leave the salaries alone, got %v", after)
+	}
+	overlaps, err := temporalDB.Audit(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(overlaps) != 0 {
+		t.Errorf("Expected no overlaps, got %v", overlaps)
+	}
+}
+
+func TestClock(t *testing.T) {
+	db, cleanup := createTestDB(t)
+	defer cleanup()
+
+	temporalDB, err := bitemporal.NewTemporalDB(db, bitemporal.WithClock(bitemporal.ClockFunc(func() time.Time {
+		return testTxnMoment
+	})))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	window := bitemporal.UpdateWindow{
+		Table:     "salaries",
+		Select:    []string{"emp_no", "salary"},
+		FilterBy:  []string{"emp_no"},
+		ValidFrom: bitemporal.AsTime("1995-01-01"),
+		ValidTo:   bitemporal.AsTime("2000-01-01"),
+		Values:    map[string]any{"emp_no": 10009, "salary": 42},
+	}
+
+	if _, err = bitemporal.CreatePeriodsQuery(window); err != nil {
+		t.Errorf("Expected CreatePeriodsQuery to default the transaction moment, got %v", err)
+	}
+
+	// the known_at of a request never back-dates a write
+	ctx := bitemporal.WithSystemMoment(context.Background(), bitemporal.AsTime("2025-08-01"))
+	periods, err := temporalDB.ApplyUpdateWindow(ctx, window)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, period := range periods {
+		if !period.TxnOpen.Equal(testTxnMoment) {
+			t.Errorf("Expected period to be recorded at the clock's moment, got %s", period.Entity)
+		}
+	}
+
+	var moments []string
+	rows, err := db.Query("SELECT DISTINCT txn_open FROM salaries WHERE DATETIME(txn_open) > '2025-08-24' UNION SELECT DISTINCT txn_close FROM salaries WHERE DATETIME(txn_close) < '9999-12-31'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var moment string
+		if err = rows.Scan(&moment); err != nil {
+			t.Fatal(err)
+		}
+		moments = append(moments, moment)
+	}
+	if len(moments) != 1 {
+		t.Errorf("Expected every record of the write to share one transaction moment, got %v", moments)
+	}
+
+	// the TxnMoment of the TemporalContext takes precedence over the clock
+	ctx = bitemporal.WithTxnMoment(context.Background(), testTxnMoment.Add(time.Hour))
+	periods, err = temporalDB.ApplyUpdateWindow(ctx, window)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, period := range periods {
+		if !period.TxnOpen.Equal(testTxnMoment.Add(time.Hour)) {
+			t.Errorf("Expected period to be recorded at the context's transaction moment, got %s", period.Entity)
+		}
+	}
+}
@@ -1,25 +1,50 @@
 package bitemporal
 
 import (
-	"errors"
+	"fmt"
+	"net/http"
+	"strings"
 	"time"
 )
 
-// AsTime attempts to convert a few common date formats to a time.Time
-// This exists purely for developer laziness
-func AsTime(s string) time.Time {
-	layouts := []string{
-		time.DateTime,
-		time.DateOnly,
-		"2006-01-02 15:04:05-07:00",
-	}
+// timeLayouts are tried in order by ParseTime, fractional seconds are optional in each of them
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	// how go-sqlite3 stores a time.Time
+	"2006-01-02 15:04:05.999999999-07:00",
+	time.DateOnly,
+	http.TimeFormat,
+}
 
-	for _, layout := range layouts {
-		t, err := time.Parse(layout, s)
-		if err == nil {
-			return t
+// ParseTime parses RFC 3339, date-only and date-time strings, the forms SQLite stores times in such as
+// "2025-08-23 08:55:49.371425-07:00", HTTP dates, and the literals "now", "infinity" (EndOfTime) and "-infinity"
+// (StartOfTime). Times without a zone are taken as UTC and every time is returned in UTC.
+func ParseTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	switch strings.ToLower(s) {
+	case "now":
+		return time.Now().UTC(), nil
+	case "infinity", "+infinity":
+		return EndOfTime, nil
+	case "-infinity":
+		return StartOfTime, nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
 		}
 	}
+	return time.Time{}, fmt.Errorf("unrecognised time %q", s)
+}
 
-	panic(errors.New(s))
+// AsTime is ParseTime for literals known to be well-formed, it panics otherwise
+// This exists purely for developer laziness
+func AsTime(s string) time.Time {
+	t, err := ParseTime(s)
+	if err != nil {
+		panic(err)
+	}
+	return t
 }
@@ -4,9 +4,12 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"testing"
+	"time"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/olekukonko/tablewriter/tw"
+	"github.com/pborges/bitemporal"
 )
 
 func highlight(highlight map[any]int, val any) string {
@@ -63,3 +66,44 @@ func PrintSalaryTable(salary int64, validFrom string, validTo string, rows []Sal
 
 	table.Render()
 }
+
+func TestParseTime(t *testing.T) {
+	moment := time.Date(2025, 8, 23, 15, 55, 49, 371425000, time.UTC)
+	tests := []struct {
+		input    string
+		expected time.Time
+	}{
+		{"2025-08-23T08:55:49.371425-07:00", moment},
+		{"2025-08-23T15:55:49.371425Z", moment},
+		{"2025-08-23 08:55:49.371425-07:00", moment},
+		{"2025-08-23 15:55:49.371425", moment},
+		{"2025-08-23T15:55:49.371425", moment},
+		{"2025-08-23 15:55:49", moment.Truncate(time.Second)},
+		{" 2025-08-23 ", time.Date(2025, 8, 23, 0, 0, 0, 0, time.UTC)},
+		{"Sat, 23 Aug 2025 15:55:49 GMT", moment.Truncate(time.Second)},
+		{"infinity", bitemporal.EndOfTime},
+		{"-infinity", bitemporal.StartOfTime},
+	}
+	for _, test := range tests {
+		actual, err := bitemporal.ParseTime(test.input)
+		if err != nil {
+			t.Errorf("ParseTime(%q): %s", test.input, err)
+			continue
+		}
+		if !actual.Equal(test.expected) || actual.Location() != time.UTC {
+			t.Errorf("ParseTime(%q) = %s, expected %s", test.input, actual, test.expected)
+		}
+	}
+
+	before := time.Now()
+	now, err := bitemporal.ParseTime("now")
+	if err != nil || now.Before(before) || now.Location() != time.UTC {
+		t.Errorf("ParseTime(\"now\") = %s, %v", now, err)
+	}
+
+	for _, input := range []string{"", "yesterday", "2025-13-01", "2025-08-23 25:00:00"} {
+		if _, err = bitemporal.ParseTime(input); err == nil {
+			t.Errorf("Expected ParseTime(%q) to fail", input)
+		}
+	}
+}
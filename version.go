@@ -0,0 +1,62 @@
+package bitemporal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrVersionConflict is returned by writes whose expected version is no longer the version of their key
+var ErrVersionConflict = errors.New("version conflict")
+
+// Version returns the last transaction moment at which a record of the key was opened or closed, or the zero time
+// when the key has never been written. Any write to the key changes its version, which makes it usable as an
+// optimistic lock through UpdateWindow.ExpectedVersion.
+func (repo *TemporalDB) Version(ctx context.Context, table string, key map[string]any) (time.Time, error) {
+	t, ok := repo.table(table)
+	if !ok {
+		return time.Time{}, fmt.Errorf("table %q is not registered", table)
+	}
+	filterBy, err := keyColumns(t, key)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return repo.keyVersion(ctx, repo.db, t.Name, filterBy, key)
+}
+
+func (repo *TemporalDB) keyVersion(ctx context.Context, q querier, table string, filterBy []string, key map[string]any) (time.Time, error) {
+	filters := make([]string, len(filterBy))
+	args := make(map[string]any, len(filterBy))
+	for i, column := range filterBy {
+		filters[i] = column + " = @" + column
+		args[column] = key[column]
+	}
+	where := strings.Join(filters, " AND ")
+
+	query := fmt.Sprintf(`SELECT MAX(moment) FROM (
+    SELECT txn_open moment FROM %[1]s WHERE %[2]s
+    UNION ALL
+    SELECT txn_close FROM %[1]s WHERE %[2]s AND txn_close < %[3]s
+) versions`, table, where, repo.dialect.Infinity())
+
+	query, bound := repo.bind(QueryFragment{query, args})
+	rows, err := q.QueryContext(ctx, query, bound...)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer rows.Close()
+
+	var moment sql.NullString
+	if rows.Next() {
+		if err = rows.Scan(&moment); err != nil {
+			return time.Time{}, err
+		}
+	}
+	if err = rows.Err(); err != nil || !moment.Valid {
+		return time.Time{}, err
+	}
+	return asMoment(moment.String)
+}